@@ -0,0 +1,211 @@
+// Package addr implements Bech32 (BIP-173) and Bech32m (BIP-350) SegWit
+// address encoding, plus the BIP-341 Taproot key-path-only output-key
+// tweak, as a standalone package so both the main brute-forcer and the
+// bench package's benchmarks can generate P2WPKH/P2TR addresses without
+// duplicating this logic.
+//
+// btcutil's bech32 package (v1.0.2, vendored before Taproot existed) only
+// knows the BIP-173 checksum constant, so the polymod implementation below
+// supports both the BIP-173 and BIP-350 constants directly rather than
+// pulling in a newer btcutil.
+package addr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	sha256simd "github.com/minio/sha256-simd"
+)
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// bech32ConstBIP173 is the checksum XOR constant for witness version 0
+// addresses (P2WPKH/P2WSH).
+const bech32ConstBIP173 = 1
+
+// bech32ConstBIP350 is the checksum XOR constant for witness version 1+
+// addresses (P2TR), a.k.a. Bech32m.
+const bech32ConstBIP350 = 0x2bc830a3
+
+// bech32Polymod computes the BIP-173/BIP-350 checksum polymod over a slice
+// of 5-bit values. It is the same generator polynomial for both variants;
+// only the final XOR constant differs.
+func bech32Polymod(values []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+// bech32HRPExpand expands the human-readable part into the 5-bit values
+// used as part of the checksum, per BIP-173.
+func bech32HRPExpand(hrp string) []byte {
+	out := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+// convertBits re-packs a byte slice from fromBits-wide groups to toBits-wide
+// groups, used to turn an 8-bit witness program into the 5-bit groups that
+// Bech32 encodes.
+func convertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	acc, bits := uint32(0), uint(0)
+	maxVal := uint32(1)<<toBits - 1
+	var out []byte
+	for _, b := range data {
+		acc = acc<<fromBits | uint32(b)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte(acc>>bits)&byte(maxVal))
+		}
+	}
+	if pad && bits > 0 {
+		out = append(out, byte(acc<<(toBits-bits))&byte(maxVal))
+	}
+	return out, nil
+}
+
+// EncodeSegwit encodes a witness version + program as a Bech32 (version 0)
+// or Bech32m (version 1+) address, per BIP-173/BIP-350.
+func EncodeSegwit(hrp string, witnessVersion byte, program []byte) (string, error) {
+	data, err := convertBits(program, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	data = append([]byte{witnessVersion}, data...)
+
+	constant := uint32(bech32ConstBIP173)
+	if witnessVersion != 0 {
+		constant = bech32ConstBIP350
+	}
+
+	values := append(bech32HRPExpand(hrp), data...)
+	values = append(values, 0, 0, 0, 0, 0, 0)
+	mod := bech32Polymod(values) ^ constant
+
+	checksum := make([]byte, 6)
+	for i := 0; i < 6; i++ {
+		checksum[i] = byte((mod >> uint(5*(5-i))) & 31)
+	}
+
+	combined := append(data, checksum...)
+	out := make([]byte, 0, len(hrp)+1+len(combined))
+	out = append(out, hrp...)
+	out = append(out, '1')
+	for _, b := range combined {
+		out = append(out, bech32Charset[b])
+	}
+	return string(out), nil
+}
+
+// DecodeSegwit decodes a Bech32 or Bech32m SegWit address back into its
+// witness version and program, validating the checksum against whichever of
+// the BIP-173/BIP-350 constants matches the decoded witness version.
+func DecodeSegwit(address string) (witnessVersion byte, program []byte, err error) {
+	sep := strings.LastIndexByte(address, '1')
+	if sep < 1 || sep+7 > len(address) {
+		return 0, nil, fmt.Errorf("invalid bech32 address: %q", address)
+	}
+	hrp, data := address[:sep], address[sep+1:]
+
+	values := make([]byte, len(data))
+	for i := 0; i < len(data); i++ {
+		idx := strings.IndexByte(bech32Charset, data[i])
+		if idx < 0 {
+			return 0, nil, fmt.Errorf("invalid bech32 character %q", data[i])
+		}
+		values[i] = byte(idx)
+	}
+	if len(values) < 7 {
+		return 0, nil, fmt.Errorf("bech32 payload too short")
+	}
+
+	witnessVersion = values[0]
+	constant := uint32(bech32ConstBIP173)
+	if witnessVersion != 0 {
+		constant = bech32ConstBIP350
+	}
+
+	checksumInput := append(bech32HRPExpand(hrp), values...)
+	if bech32Polymod(checksumInput) != constant {
+		return 0, nil, fmt.Errorf("invalid bech32 checksum in %q", address)
+	}
+
+	program, err = convertBits(values[1:len(values)-6], 5, 8, false)
+	if err != nil {
+		return 0, nil, err
+	}
+	return witnessVersion, program, nil
+}
+
+// EncodeP2WPKH Bech32-encodes a 20-byte Hash160 as a mainnet native SegWit
+// (bc1q...) address. hash160 is always a valid witness program, so unlike
+// EncodeSegwit this can't fail and is kept allocation-lean for the hot loop.
+func EncodeP2WPKH(hash160 []byte) string {
+	address, _ := EncodeSegwit("bc", 0, hash160)
+	return address
+}
+
+// EncodeP2TR Bech32m-encodes a 32-byte BIP-341 x-only output key as a
+// mainnet Taproot (bc1p...) address. Callers derive xonly with
+// TaprootOutputKey first.
+func EncodeP2TR(xonly [32]byte) string {
+	address, _ := EncodeSegwit("bc", 1, xonly[:])
+	return address
+}
+
+// TaggedHash implements the BIP-340 tagged hash: SHA256(SHA256(tag) ||
+// SHA256(tag) || msg).
+func TaggedHash(tag string, msg []byte) [32]byte {
+	tagHash := sha256simd.Sum256([]byte(tag))
+	buf := make([]byte, 0, 64+len(msg))
+	buf = append(buf, tagHash[:]...)
+	buf = append(buf, tagHash[:]...)
+	buf = append(buf, msg...)
+	return sha256simd.Sum256(buf)
+}
+
+// TaprootOutputKey computes the BIP-341 key-path-only Taproot output key
+// Q = P + int(H_TapTweak(P_x))*G for a given internal (compressed) public
+// key, returning its 32-byte x-only serialization.
+func TaprootOutputKey(pub *btcec.PublicKey) ([32]byte, error) {
+	// BIP-341 works with the even-Y ("x-only") internal key.
+	xBytes := [32]byte{}
+	pub.X().FillBytes(xBytes[:])
+
+	internal, err := btcec.ParsePubKey(append([]byte{0x02}, xBytes[:]...))
+	if err != nil {
+		return [32]byte{}, err
+	}
+
+	tweakHash := TaggedHash("TapTweak", xBytes[:])
+
+	var tweakScalar btcec.ModNScalar
+	tweakScalar.SetBytes(&tweakHash)
+
+	var tweakPoint, internalPoint, outputPoint btcec.JacobianPoint
+	internal.AsJacobian(&internalPoint)
+	btcec.ScalarBaseMultNonConst(&tweakScalar, &tweakPoint)
+	btcec.AddNonConst(&internalPoint, &tweakPoint, &outputPoint)
+	outputPoint.ToAffine()
+
+	var out [32]byte
+	outputPoint.X.PutBytesUnchecked(out[:])
+	return out, nil
+}
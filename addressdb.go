@@ -0,0 +1,362 @@
+package main
+
+/*
+AddressDB: On-Disk Target Database
+
+readAddresses's map[string]bool is fine for a few million addresses, but a
+40M+ line dump costs gigabytes of RAM just to hold Base58 strings. AddressDB
+is an alternative backend for the raw target data, selected with -db instead
+of a text address file: a Bolt key-value file opened read-only (and
+therefore memory-mapped by bbolt itself), so a huge target set no longer has
+to fit in RAM at all.
+
+Crucially, AddressDB is keyed by raw pubkey-hash bytes, not Base58 strings:
+the worker only Base58/Bech32-encodes a candidate once it already has a hit,
+so the hot (overwhelmingly negative) path skips address encoding entirely.
+
+A Bloom filter built over every imported key is stored alongside the Bolt
+buckets and loaded into memory on open, so the overwhelming majority of
+lookups - which are misses - never touch the mmap'd B-tree at all; only a
+(rare) filter hit falls through to the real Bolt lookup.
+
+The "import" subcommand (see main's subcommand dispatch) builds the on-disk
+database from a text address dump ahead of time.
+*/
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/base58"
+	"go.etcd.io/bbolt"
+
+	"github.com/Asylian21/btc-brute-force/addr"
+)
+
+var addressDBBucket = []byte("hash160")
+
+// addressDBBloomBucket holds the serialized Bloom filter built over every
+// key in addressDBBucket at import time (see saveBloomFilter/loadBloomFilter
+// below). Absent on databases imported before this existed.
+var addressDBBloomBucket = []byte("bloom")
+
+// defaultAddressDBFilterFPR is the Bloom filter false-positive rate used by
+// the `import` subcommand: tight enough that the exact Bolt lookup below it
+// is rarely reached, without the filter itself costing much RAM.
+const defaultAddressDBFilterFPR = 1e-4
+
+// AddressDB answers membership queries for raw pubkey-hash / witness-program
+// bytes, backed by either an in-memory set or an on-disk store.
+type AddressDB interface {
+	Contains(key []byte) bool
+	Close() error
+}
+
+// boltAddressDB is the on-disk AddressDB backend: a read-only Bolt file.
+// Bolt memory-maps its data file, so lookups are page-cache hits rather than
+// Go-heap hash map probes once the OS has warmed the pages. filter, when
+// non-nil, is consulted before the Bolt file on every lookup.
+type boltAddressDB struct {
+	db     *bbolt.DB
+	bucket []byte
+	filter *BloomFilter
+}
+
+// openBoltAddressDB opens an existing Bolt database (built via the `import`
+// subcommand) for read-only querying, loading its Bloom filter into memory
+// if the database has one.
+func openBoltAddressDB(path string) (*boltAddressDB, error) {
+	db, err := bbolt.Open(path, 0444, &bbolt.Options{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("opening address db %q: %w", path, err)
+	}
+
+	var filter *BloomFilter
+	if err := db.View(func(tx *bbolt.Tx) error {
+		filter, err = loadBloomFilter(tx)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("loading bloom filter from %q: %w", path, err)
+	}
+
+	return &boltAddressDB{db: db, bucket: addressDBBucket, filter: filter}, nil
+}
+
+func (b *boltAddressDB) Contains(key []byte) bool {
+	if b.filter != nil && !b.filter.MightContain(string(key)) {
+		return false
+	}
+
+	var found bool
+	// Errors here can only come from Bolt's own bookkeeping (e.g. a
+	// concurrently-closed DB); either way the lookup just reports a miss.
+	_ = b.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(b.bucket)
+		if bucket == nil {
+			return nil
+		}
+		found = bucket.Get(key) != nil
+		return nil
+	})
+	return found
+}
+
+func (b *boltAddressDB) Close() error {
+	return b.db.Close()
+}
+
+// saveBloomFilter persists filter into tx's bloom bucket as three keys: "m"
+// and "k" (8-byte little-endian counts) and "bits" (the packed bitset,
+// little-endian per word), so loadBloomFilter can reconstruct it exactly.
+func saveBloomFilter(tx *bbolt.Tx, filter *BloomFilter) error {
+	bucket, err := tx.CreateBucketIfNotExists(addressDBBloomBucket)
+	if err != nil {
+		return err
+	}
+
+	mBytes := make([]byte, 8)
+	binary.LittleEndian.PutUint64(mBytes, filter.m)
+	if err := bucket.Put([]byte("m"), mBytes); err != nil {
+		return err
+	}
+	kBytes := make([]byte, 8)
+	binary.LittleEndian.PutUint64(kBytes, uint64(filter.k))
+	if err := bucket.Put([]byte("k"), kBytes); err != nil {
+		return err
+	}
+
+	bits := make([]byte, len(filter.bits)*8)
+	for i, word := range filter.bits {
+		binary.LittleEndian.PutUint64(bits[i*8:], word)
+	}
+	return bucket.Put([]byte("bits"), bits)
+}
+
+// loadBloomFilter reconstructs the Bloom filter saved by saveBloomFilter, or
+// returns (nil, nil) if tx's database predates this feature and has no
+// bloom bucket.
+func loadBloomFilter(tx *bbolt.Tx) (*BloomFilter, error) {
+	bucket := tx.Bucket(addressDBBloomBucket)
+	if bucket == nil {
+		return nil, nil
+	}
+
+	mBytes, kBytes, bitsBytes := bucket.Get([]byte("m")), bucket.Get([]byte("k")), bucket.Get([]byte("bits"))
+	if len(mBytes) != 8 || len(kBytes) != 8 || len(bitsBytes)%8 != 0 {
+		return nil, fmt.Errorf("malformed bloom bucket")
+	}
+
+	bits := make([]uint64, len(bitsBytes)/8)
+	for i := range bits {
+		bits[i] = binary.LittleEndian.Uint64(bitsBytes[i*8:])
+	}
+
+	return &BloomFilter{
+		bits: bits,
+		m:    binary.LittleEndian.Uint64(mBytes),
+		k:    uint(binary.LittleEndian.Uint64(kBytes)),
+	}, nil
+}
+
+// ============================================================================
+// IMPORT SUBCOMMAND
+// ============================================================================
+
+// decodeAddressToKey decodes a single text address into the raw bytes it
+// should be keyed by in AddressDB: the 20-byte Hash160 for Base58Check
+// P2PKH/P2SH addresses, or the witness program for Bech32/Bech32m addresses.
+func decodeAddressToKey(address string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(address, "bc1"):
+		_, program, err := addr.DecodeSegwit(address)
+		return program, err
+	default:
+		decoded, version, err := base58.CheckDecode(address)
+		if err != nil {
+			return nil, err
+		}
+		if version != 0x00 && version != 0x05 {
+			return nil, fmt.Errorf("unsupported Base58Check version byte 0x%02x", version)
+		}
+		return decoded, nil
+	}
+}
+
+// importAddressDB reads a newline-delimited address dump and writes every
+// address's raw key into a fresh Bolt database at dbPath, deduplicating via
+// the bucket's own unique-key semantics. Lines that fail to decode are
+// skipped with a warning rather than aborting the whole import.
+func importAddressDB(inputPath, dbPath string) error {
+	in, err := os.Open(inputPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	db, err := bbolt.Open(dbPath, 0644, nil)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(addressDBBucket)
+		if err != nil {
+			return err
+		}
+
+		// Keys are kept around (not just written to Bolt) so a Bloom filter
+		// can be built over all of them once the final count is known; this
+		// only costs RAM for the duration of the import, not at query time.
+		var keys [][]byte
+		scanner := bufio.NewScanner(in)
+		imported, skipped := 0, 0
+		for scanner.Scan() {
+			addr := strings.TrimSpace(scanner.Text())
+			if addr == "" {
+				continue
+			}
+			key, err := decodeAddressToKey(addr)
+			if err != nil {
+				logImportSkip(addr, err)
+				skipped++
+				continue
+			}
+			if err := bucket.Put(key, nil); err != nil {
+				return err
+			}
+			keys = append(keys, key)
+			imported++
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+
+		filter := NewBloomFilter(imported, defaultAddressDBFilterFPR)
+		for _, key := range keys {
+			filter.Add(string(key))
+		}
+		if err := saveBloomFilter(tx, filter); err != nil {
+			return err
+		}
+
+		fmt.Printf("Imported %d addresses into %s (%d skipped)\n", imported, dbPath, skipped)
+		return nil
+	})
+}
+
+// logImportSkip reports a single undecodable address during import. Broken
+// out so the scan loop above stays readable.
+func logImportSkip(addr string, err error) {
+	fmt.Fprintf(os.Stderr, "skipping %q: %s\n", addr, err)
+}
+
+// runImportCommand implements the `import <btc-address-file.txt> <db-file>`
+// subcommand dispatched from main().
+func runImportCommand(args []string) {
+	if len(args) != 2 {
+		fmt.Println("Usage: ./bitcoin-wallet-bruteforce-offline import <btc-address-file.txt> <db-file>")
+		os.Exit(1)
+	}
+	if err := importAddressDB(args[0], args[1]); err != nil {
+		log.Fatalf("Import failed: %s", err)
+	}
+}
+
+// ============================================================================
+// DB-BACKED WORKER
+// ============================================================================
+
+/*
+workerDB is the AddressDB counterpart of worker(): instead of generating and
+Base58/Bech32-encoding all four address formats up front, it derives the raw
+key bytes each format would be stored under and checks those directly against
+the database, so the (overwhelmingly negative) hot path never touches Base58
+or Bech32 encoding at all. Only once a key actually hits does it pay for
+encoding, in order to produce a human-readable match.
+
+Three raw keys are checked per generated key pair:
+  - hash160(pubkey)                     - covers P2PKH and P2WPKH, which
+    share the same pubkey hash
+  - hash160(0x00 0x14 || hash160(pubkey)) - covers P2SH-P2WPKH
+  - BIP-341 x-only output key            - covers P2TR
+*/
+func workerDB(ctx context.Context, id int, wg *sync.WaitGroup, db AddressDB, matchChan chan<- MatchResult, counter *uint64) {
+	defer wg.Done()
+
+	localCounter := uint64(0)
+	const updateInterval = 10000
+
+	for ctx.Err() == nil {
+		privateKey, err := btcec.NewPrivateKey()
+		if err != nil {
+			log.Printf("Worker %d: Failed to generate key: %s", id, err)
+			continue
+		}
+		pubKey := privateKey.PubKey()
+		pubKeyBytes := pubKey.SerializeCompressed()
+		pubKeyHash := btcutil.Hash160(pubKeyBytes)
+
+		redeemScript := append([]byte{0x00, 0x14}, pubKeyHash...)
+		scriptHash := btcutil.Hash160(redeemScript)
+
+		xonly, err := addr.TaprootOutputKey(pubKey)
+		if err != nil {
+			log.Printf("Worker %d: Failed to derive taproot key: %s", id, err)
+			continue
+		}
+
+		localCounter++
+		if localCounter%updateInterval == 0 {
+			atomic.AddUint64(counter, updateInterval)
+			localCounter = 0
+		}
+
+		candidates := [...]struct {
+			key    []byte
+			format string
+		}{
+			{pubKeyHash, "P2PKH/P2WPKH"},
+			{scriptHash, "P2SH-P2WPKH"},
+			{xonly[:], "P2TR"},
+		}
+		for _, candidate := range candidates {
+			if !db.Contains(candidate.key) {
+				continue
+			}
+			// *** MATCH FOUND! *** - only now is the address encoded.
+			address, encodeErr := describeRawMatch(candidate.key, candidate.format, pubKeyHash, pubKey)
+			if encodeErr != nil {
+				log.Printf("Worker %d: Failed to encode matched address: %s", id, encodeErr)
+				continue
+			}
+			fmt.Printf("\n*** MATCH FOUND! ***\nAddress: %s (%s)\n\n", address, candidate.format)
+			matchChan <- MatchResult{privateKey: privateKey, address: address, format: candidate.format}
+		}
+	}
+}
+
+// describeRawMatch renders the human-readable address for a raw AddressDB
+// hit, used only once a match has already been confirmed.
+func describeRawMatch(key []byte, format string, pubKeyHash []byte, pubKey *btcec.PublicKey) (string, error) {
+	switch format {
+	case "P2PKH/P2WPKH":
+		return generateP2WPKH(pubKeyHash)
+	case "P2SH-P2WPKH":
+		return generateP2SHWPKH(pubKeyHash)
+	case "P2TR":
+		return generateP2TR(pubKey)
+	default:
+		return "", fmt.Errorf("unknown format %q", format)
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+/*
+AddressSet: Bloom-Filtered Target Matching
+
+A 40M-address dump loaded as a plain map[string]bool is ~4GB and, more
+importantly, each lookup on a near-certain miss still has to chase a
+randomly-placed bucket through L2/L3 cache. Since the overwhelming majority
+of generated addresses are misses, we put a small, cache-friendly Bloom
+filter in front of the map: a negative filter test means a guaranteed miss
+and skips the map entirely, while a (rare) filter hit falls through to the
+exact map lookup to rule out the filter's false positives.
+
+AddressSet is the interface the worker loop checks candidates against, so it
+doesn't need to know whether it's talking to a bare map or a filter+map pair.
+*/
+
+import (
+	"container/list"
+	"math"
+	"sync"
+
+	sha256simd "github.com/minio/sha256-simd"
+)
+
+// AddressSet answers set-membership queries for generated addresses. The
+// worker loop is agnostic to the backing implementation.
+type AddressSet interface {
+	Contains(address string) bool
+}
+
+// MutableAddressSet is an AddressSet that can also grow at runtime, for
+// sources that discover new target addresses while the search is already
+// running (see sources/blockbook and the -blockbook-ws flag).
+type MutableAddressSet interface {
+	AddressSet
+	Add(address string)
+}
+
+// mapAddressSet is the simplest possible AddressSet: a direct map lookup,
+// with no Bloom filter in front of it.
+type mapAddressSet map[string]bool
+
+func (m mapAddressSet) Contains(address string) bool {
+	return m[address]
+}
+
+// filteredAddressSet consults a Bloom filter before falling back to the
+// exact map, so the hot path avoids the map's cache-unfriendly lookup on
+// the vast majority of (non-matching) candidates.
+type filteredAddressSet struct {
+	filter *BloomFilter
+	exact  map[string]bool
+}
+
+func (f *filteredAddressSet) Contains(address string) bool {
+	if !f.filter.MightContain(address) {
+		return false
+	}
+	return f.exact[address]
+}
+
+// newAddressSet wraps a loaded address map with a Bloom filter sized for the
+// map's cardinality at the requested false-positive rate. A non-positive or
+// >=1 fpr disables the filter and falls back to a bare map lookup.
+func newAddressSet(addresses map[string]bool, fpr float64) AddressSet {
+	if fpr <= 0 || fpr >= 1 {
+		return mapAddressSet(addresses)
+	}
+
+	filter := NewBloomFilter(len(addresses), fpr)
+	for addr := range addresses {
+		filter.Add(addr)
+	}
+	return &filteredAddressSet{filter: filter, exact: addresses}
+}
+
+// lruAddressSet is a thread-safe, capacity-bounded MutableAddressSet backing
+// -blockbook-ws: addresses stream in continuously from a live feed, so unlike
+// mapAddressSet/filteredAddressSet it has to support concurrent writes, and
+// an LRU eviction policy keeps RAM bounded regardless of how long the feed
+// has been running.
+type lruAddressSet struct {
+	mu       sync.RWMutex
+	cap      int
+	elements map[string]*list.Element
+	order    *list.List // front = most recently added, back = eviction candidate
+}
+
+// newLRUAddressSet creates an empty lruAddressSet holding at most capacity
+// addresses, preloaded with the given starting addresses (in map iteration
+// order, so which of them are evicted first is not defined).
+func newLRUAddressSet(capacity int, preload map[string]bool) *lruAddressSet {
+	s := &lruAddressSet{
+		cap:      capacity,
+		elements: make(map[string]*list.Element, capacity),
+		order:    list.New(),
+	}
+	for addr := range preload {
+		s.Add(addr)
+	}
+	return s
+}
+
+func (s *lruAddressSet) Contains(address string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.elements[address]
+	return ok
+}
+
+// Add inserts address, evicting the least recently added entry if the set is
+// already at capacity. Re-adding an already-present address just refreshes
+// its position.
+func (s *lruAddressSet) Add(address string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.elements[address]; ok {
+		s.order.MoveToFront(elem)
+		return
+	}
+
+	s.elements[address] = s.order.PushFront(address)
+	if s.cap > 0 && len(s.elements) > s.cap {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.elements, oldest.Value.(string))
+	}
+}
+
+// ============================================================================
+// BLOOM FILTER
+// ============================================================================
+
+// BloomFilter is a fixed-size probabilistic set: MightContain never returns a
+// false negative, but may return a false positive at roughly the configured
+// rate. Bit indices are derived from a single SHA-256 hash of the element,
+// split into two 64-bit halves combined via the Kirsch-Mitzenmacher double
+// hashing technique (g_i = h1 + i*h2 mod m), avoiding k independent hashes.
+type BloomFilter struct {
+	bits []uint64 // packed bitset, m bits total
+	m    uint64   // number of bits
+	k    uint     // number of hash functions
+}
+
+// NewBloomFilter sizes a filter for n expected elements at false-positive
+// rate p:
+//
+//	m = ceil(-n * ln(p) / ln(2)^2)   (bits)
+//	k = round((m/n) * ln(2))         (hash functions)
+func NewBloomFilter(n int, p float64) *BloomFilter {
+	if n < 1 {
+		n = 1
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	if m < 64 {
+		m = 64
+	}
+	k := uint(math.Round((float64(m) / float64(n)) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+
+	return &BloomFilter{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    k,
+	}
+}
+
+// hashPair derives the two 64-bit seeds used to generate the k bit indices
+// for an element, via a single SHA-256 digest.
+func hashPair(element string) (h1, h2 uint64) {
+	sum := sha256simd.Sum256([]byte(element))
+	for i := 0; i < 8; i++ {
+		h1 = h1<<8 | uint64(sum[i])
+	}
+	for i := 8; i < 16; i++ {
+		h2 = h2<<8 | uint64(sum[i])
+	}
+	return h1, h2
+}
+
+// Add sets the k bits corresponding to element.
+func (f *BloomFilter) Add(element string) {
+	h1, h2 := hashPair(element)
+	for i := uint(0); i < f.k; i++ {
+		bit := (h1 + uint64(i)*h2) % f.m
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// MightContain reports whether element may be in the set. false means
+// definitely not in the set; true means probably in the set (subject to the
+// filter's configured false-positive rate).
+func (f *BloomFilter) MightContain(element string) bool {
+	h1, h2 := hashPair(element)
+	for i := uint(0); i < f.k; i++ {
+		bit := (h1 + uint64(i)*h2) % f.m
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -1,12 +1,19 @@
 package bench
 
 import (
+	"crypto/rand"
+	"fmt"
 	"testing"
 
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcutil"
 	"github.com/btcsuite/btcutil/base58"
 	sha256simd "github.com/minio/sha256-simd"
+
+	"github.com/Asylian21/btc-brute-force/addr"
+	"github.com/Asylian21/btc-brute-force/hd"
+	"github.com/Asylian21/btc-brute-force/keygen"
+	"github.com/Asylian21/btc-brute-force/matcher"
 )
 
 // BenchmarkHashPipeline benchmarks the core Bitcoin address generation pipeline:
@@ -45,6 +52,35 @@ func BenchmarkHashPipeline(b *testing.B) {
 	}
 }
 
+// BenchmarkHashPipelineUncompressed reworks BenchmarkHashPipeline to derive
+// its address from the 65-byte uncompressed pubkey serialization
+// (0x04 || X || Y) instead of the 33-byte compressed one, via
+// keygen.GenerateAddresses - the serialization several well-known
+// early-era/puzzle addresses use.
+func BenchmarkHashPipelineUncompressed(b *testing.B) {
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	out := make([][]byte, 1)
+	for i := 0; i < b.N; i++ {
+		privateKey, err := btcec.NewPrivateKey()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := keygen.GenerateAddresses(privateKey, keygen.Uncompressed, out); err != nil {
+			b.Fatal(err)
+		}
+
+		buf := make([]byte, 0, 25)
+		buf = append(buf, 0x00)
+		buf = append(buf, out[0]...)
+		h1 := sha256simd.Sum256(buf)
+		h2 := sha256simd.Sum256(h1[:])
+		buf = append(buf, h2[:4]...)
+		_ = base58.Encode(buf)
+	}
+}
+
 // BenchmarkKeyGeneration benchmarks only the private/public key generation
 func BenchmarkKeyGeneration(b *testing.B) {
 	b.ResetTimer()
@@ -59,6 +95,164 @@ func BenchmarkKeyGeneration(b *testing.B) {
 	}
 }
 
+// BenchmarkBatchKeyGeneration benchmarks keygen.Batch.Generate at several
+// batch sizes, sharing one field inversion (Montgomery's trick) across
+// each batch instead of paying one per key the way BenchmarkKeyGeneration
+// does.
+func BenchmarkBatchKeyGeneration(b *testing.B) {
+	for _, size := range []int{64, 256, 1024} {
+		b.Run(fmt.Sprintf("batch=%d", size), func(b *testing.B) {
+			scalars := make([][]byte, size)
+			for i := range scalars {
+				s := make([]byte, 32)
+				if _, err := rand.Read(s); err != nil {
+					b.Fatal(err)
+				}
+				scalars[i] = s
+			}
+			out := make([][33]byte, size)
+			var batch keygen.Batch
+
+			b.ResetTimer()
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				if err := batch.Generate(scalars, out); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkHashPipelineBatched reworks BenchmarkHashPipeline to source its
+// compressed public keys batchSize at a time from keygen.Batch instead of
+// one-by-one NewPrivateKey/SerializeCompressed calls, demonstrating the
+// batch API's savings across the same end-to-end Hash160 + Base58Check
+// pipeline.
+func BenchmarkHashPipelineBatched(b *testing.B) {
+	const batchSize = 256
+
+	scalars := make([][]byte, batchSize)
+	for i := range scalars {
+		scalars[i] = make([]byte, 32)
+	}
+	out := make([][33]byte, batchSize)
+	var batch keygen.Batch
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i += batchSize {
+		n := batchSize
+		if i+n > b.N {
+			n = b.N - i
+		}
+
+		for j := 0; j < n; j++ {
+			if _, err := rand.Read(scalars[j]); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if err := batch.Generate(scalars[:n], out[:n]); err != nil {
+			b.Fatal(err)
+		}
+
+		for j := 0; j < n; j++ {
+			hash160 := btcutil.Hash160(out[j][:])
+
+			buf := make([]byte, 0, 25)
+			buf = append(buf, 0x00)
+			buf = append(buf, hash160...)
+			h1 := sha256simd.Sum256(buf)
+			h2 := sha256simd.Sum256(h1[:])
+			buf = append(buf, h2[:4]...)
+			_ = base58.Encode(buf)
+		}
+	}
+}
+
+// BenchmarkHDDerivationPipeline benchmarks deriving a BIP-44 leaf
+// (m/44'/0'/0'/0/i) from a fresh seed and rendering its legacy address:
+// seed -> master key -> account/change/index children -> compressed
+// pubkey -> Hash160 -> Base58Check. Weak passphrases turned into
+// BIP-39/BIP-32 seeds are a more realistic brute-force target than random
+// 256-bit keys, so this pipeline's cost matters as much as
+// BenchmarkHashPipeline's.
+func BenchmarkHDDerivationPipeline(b *testing.B) {
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for n := 0; n < b.N; n++ {
+		seed := make([]byte, 32)
+		if _, err := rand.Read(seed); err != nil {
+			b.Fatal(err)
+		}
+
+		key, err := hd.NewMasterKey(seed)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		path := []uint32{44 + hd.HardenedKeyStart, hd.HardenedKeyStart, hd.HardenedKeyStart, 0, uint32(n)}
+		for _, index := range path {
+			for {
+				child, err := key.Child(index)
+				if err == hd.ErrInvalidChild {
+					index++
+					continue
+				}
+				if err != nil {
+					b.Fatal(err)
+				}
+				key = child
+				break
+			}
+		}
+
+		pubKeyBytes := key.PrivateKey().PubKey().SerializeCompressed()
+		hash160 := btcutil.Hash160(pubKeyBytes)
+
+		buf := make([]byte, 0, 25)
+		buf = append(buf, 0x00)
+		buf = append(buf, hash160...)
+		h1 := sha256simd.Sum256(buf)
+		h2 := sha256simd.Sum256(h1[:])
+		buf = append(buf, h2[:4]...)
+		_ = base58.Encode(buf)
+	}
+}
+
+// BenchmarkPipelineWithMatcher benchmarks the full hash pipeline with a
+// realistic target check on the end: Private Key -> Public Key -> Hash160
+// -> matcher.Matcher.Test, against a 10,000-target Bloom-filtered Matcher
+// (none of which will ever match a freshly generated key, the same
+// overwhelmingly-negative hot path a real search runs).
+func BenchmarkPipelineWithMatcher(b *testing.B) {
+	const numTargets = 10000
+	keys := make([][20]byte, numTargets)
+	for i := range keys {
+		if _, err := rand.Read(keys[i][:]); err != nil {
+			b.Fatal(err)
+		}
+	}
+	m := matcher.New(keys, 0.001)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		privateKey, err := btcec.NewPrivateKey()
+		if err != nil {
+			b.Fatal(err)
+		}
+		hash160Bytes := btcutil.Hash160(privateKey.PubKey().SerializeCompressed())
+		var hash160 [20]byte
+		copy(hash160[:], hash160Bytes)
+		_ = m.Test(hash160)
+	}
+}
+
 // BenchmarkHash160 benchmarks the Hash160 operation (SHA256 + RIPEMD160)
 func BenchmarkHash160(b *testing.B) {
 	// Pre-generate a public key for consistent benchmarking
@@ -90,3 +284,38 @@ func BenchmarkBase58Encode(b *testing.B) {
 		_ = base58.Encode(buf)
 	}
 }
+
+// BenchmarkP2WPKHPipeline benchmarks the native SegWit address pipeline:
+// Private Key → Public Key → Hash160 → Bech32 (bc1q...) encoding.
+func BenchmarkP2WPKHPipeline(b *testing.B) {
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		privateKey, err := btcec.NewPrivateKey()
+		if err != nil {
+			b.Fatal(err)
+		}
+		hash160 := btcutil.Hash160(privateKey.PubKey().SerializeCompressed())
+		_ = addr.EncodeP2WPKH(hash160)
+	}
+}
+
+// BenchmarkP2TRPipeline benchmarks the Taproot address pipeline: Private Key
+// → Public Key → BIP-341 key-path tweak → Bech32m (bc1p...) encoding.
+func BenchmarkP2TRPipeline(b *testing.B) {
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		privateKey, err := btcec.NewPrivateKey()
+		if err != nil {
+			b.Fatal(err)
+		}
+		xonly, err := addr.TaprootOutputKey(privateKey.PubKey())
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = addr.EncodeP2TR(xonly)
+	}
+}
@@ -0,0 +1,98 @@
+package bench
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	sha256simd "github.com/minio/sha256-simd"
+)
+
+// bloomFilter is a minimal standalone Bloom filter for benchmarking purposes,
+// mirroring the BloomFilter used by the main program's AddressSet.
+type bloomFilter struct {
+	bits []uint64
+	m    uint64
+	k    uint
+}
+
+func newBloomFilter(n int, p float64) *bloomFilter {
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	k := uint(math.Round((float64(m) / float64(n)) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &bloomFilter{bits: make([]uint64, (m+63)/64), m: m, k: k}
+}
+
+func (f *bloomFilter) hashPair(s string) (h1, h2 uint64) {
+	sum := sha256simd.Sum256([]byte(s))
+	for i := 0; i < 8; i++ {
+		h1 = h1<<8 | uint64(sum[i])
+	}
+	for i := 8; i < 16; i++ {
+		h2 = h2<<8 | uint64(sum[i])
+	}
+	return h1, h2
+}
+
+func (f *bloomFilter) add(s string) {
+	h1, h2 := f.hashPair(s)
+	for i := uint(0); i < f.k; i++ {
+		bit := (h1 + uint64(i)*h2) % f.m
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+func (f *bloomFilter) mightContain(s string) bool {
+	h1, h2 := f.hashPair(s)
+	for i := uint(0); i < f.k; i++ {
+		bit := (h1 + uint64(i)*h2) % f.m
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// buildTargetSet creates n fake target addresses, a map of them, and a
+// Bloom filter front end for them.
+func buildTargetSet(n int) (map[string]bool, *bloomFilter) {
+	m := make(map[string]bool, n)
+	f := newBloomFilter(n, 1e-7)
+	for i := 0; i < n; i++ {
+		addr := fmt.Sprintf("1FakeAddress%020d", i)
+		m[addr] = true
+		f.add(addr)
+	}
+	return m, f
+}
+
+// BenchmarkMapOnlyLookup measures plain map[string]bool lookups for
+// addresses that are (by construction) always misses - the overwhelmingly
+// common case in real brute-force runs.
+func BenchmarkMapOnlyLookup(b *testing.B) {
+	targets, _ := buildTargetSet(1_000_000)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		candidate := fmt.Sprintf("1MissAddress%020d", i)
+		_ = targets[candidate]
+	}
+}
+
+// BenchmarkFilterThenMapLookup measures the two-tier Bloom-filter-then-map
+// lookup for the same miss-heavy workload.
+func BenchmarkFilterThenMapLookup(b *testing.B) {
+	targets, filter := buildTargetSet(1_000_000)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		candidate := fmt.Sprintf("1MissAddress%020d", i)
+		if filter.mightContain(candidate) {
+			_ = targets[candidate]
+		}
+	}
+}
@@ -4,14 +4,16 @@ Bitcoin Wallet Bruteforce - Offline Version
 Description:
 	This program performs brute-force generation of Bitcoin private keys and addresses,
 	checking them against a pre-loaded database of existing Bitcoin addresses.
-	It generates Legacy P2PKH addresses (starting with '1') using compressed public keys.
+	Each generated key is checked in every address format it can produce:
+	Legacy P2PKH ('1...'), P2SH-wrapped SegWit ('3...'), native SegWit Bech32
+	('bc1q...'), and Taproot Bech32m ('bc1p...').
 
 Algorithm:
 	1. Load target addresses into memory (hash map for O(1) lookup)
 	2. Generate random private keys using cryptographically secure RNG
 	3. Derive public key from private key (SECP256k1 elliptic curve)
-	4. Create P2PKH address: Base58(version + RIPEMD160(SHA256(pubkey)) + checksum)
-	5. Check if generated address exists in target database
+	4. Derive P2PKH, P2SH-P2WPKH, P2WPKH, and P2TR addresses from that one key
+	5. Check each derived address against the target database
 	6. Save matches to output file
 
 Address Database:
@@ -38,21 +40,35 @@ License: MIT
 package main
 
 import (
-	"bufio"        // Buffered I/O for efficient file reading/writing
-	"encoding/hex" // Hex encoding for private key output
-	"fmt"          // Formatted I/O
-	"log"          // Logging errors
-	"os"           // OS operations (file handling, arguments)
-	"runtime"      // Runtime information (CPU cores)
-	"strconv"      // String to integer conversion
-	"sync"         // Synchronization primitives (WaitGroup, Pool)
-	"sync/atomic"  // Atomic operations for thread-safe counters
-	"time"         // Time operations for statistics
+	"bufio"            // Buffered I/O for efficient file reading/writing
+	"context"          // Cancellation for graceful shutdown and the blockbook live-feed goroutine
+	"crypto/rand"      // Generates a fresh -checkpoint stream seed for -mode random
+	"encoding/hex"     // Hex encoding for private key output
+	"encoding/json"    // Encoding for the encrypted match keystore (see keystore.go)
+	"flag"             // CLI flag parsing
+	"fmt"              // Formatted I/O
+	"log"              // Logging errors
+	"math/big"         // Arbitrary-precision keyspace arithmetic for -mode range
+	"net/http"         // Serves -pprof's profiling endpoints
+	_ "net/http/pprof" // Registers the /debug/pprof/* handlers on http.DefaultServeMux
+	"os"               // OS operations (file handling, arguments)
+	"os/signal"        // SIGINT/SIGTERM handling for graceful shutdown
+	"runtime"          // Runtime information (CPU cores)
+	"runtime/trace"    // Per-worker, per-phase execution tracing (see -trace)
+	"strconv"          // String to integer conversion
+	"strings"          // Address trimming
+	"sync"             // Synchronization primitives (WaitGroup, Pool)
+	"sync/atomic"      // Atomic operations for thread-safe counters
+	"syscall"          // SIGTERM signal constant
+	"time"             // Time operations for statistics
 
 	"github.com/btcsuite/btcd/btcec/v2"       // Bitcoin SECP256k1 elliptic curve operations
 	"github.com/btcsuite/btcutil"             // Bitcoin utility functions (Hash160)
 	"github.com/btcsuite/btcutil/base58"      // Base58 encoding for addresses
 	sha256simd "github.com/minio/sha256-simd" // SIMD-accelerated SHA256 (2-3x faster)
+
+	"github.com/Asylian21/btc-brute-force/sources/balance"   // Live balance/tx-history lookups
+	"github.com/Asylian21/btc-brute-force/sources/blockbook" // Live Blockbook address feed
 )
 
 // ============================================================================
@@ -133,8 +149,15 @@ func readAddresses(filePath string) (map[string]bool, error) {
 	// Use buffered scanner for efficient line-by-line reading
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
+		// Mixed-format dumps (legacy/P2SH/Bech32/Taproot side by side) tend to
+		// carry stray whitespace and blank separator lines; strip both so they
+		// don't end up as bogus entries that can never match.
+		addr := strings.TrimSpace(scanner.Text())
+		if addr == "" {
+			continue
+		}
 		// Add address to hash map (value 'true' is arbitrary, we only need the key)
-		addresses[scanner.Text()] = true
+		addresses[addr] = true
 	}
 
 	// Check if scanner encountered any errors
@@ -245,7 +268,17 @@ MatchResult represents a successful match between generated and target address.
 
 Fields:
   - privateKey: The private key that generated the matching address
-  - address: The matching Bitcoin address (P2PKH format)
+  - address: The matching Bitcoin address
+  - format: Which derived address format matched, since a single key now
+    produces several candidates: "<network>:<type>" (e.g. "Bitcoin:P2PKH";
+    type is P2PKH, P2SH-P2WPKH, P2WPKH, P2TR or CashAddr), with " [range-N]"
+    appended in -mode range and " [balance-check]" for balance hits;
+    workerDB() reports "P2PKH/P2WPKH", "P2SH-P2WPKH" or "P2TR" instead
+  - mnemonic, derivationPath, xprv: set only by workerHD, so a wallet found
+    in -mode hd can be fully reconstructed (not just the one matching key) -
+    empty for worker()/workerDB() matches
+  - vanityPrefix: set only by vanityWorker, the -vanity-prefixes entry this
+    address matched - empty for every other mode
 
 Purpose:
 
@@ -253,8 +286,13 @@ Purpose:
 	matchWriter goroutine for asynchronous file writing.
 */
 type MatchResult struct {
-	privateKey *btcec.PrivateKey
-	address    string
+	privateKey     *btcec.PrivateKey
+	address        string
+	format         string
+	mnemonic       string
+	derivationPath string
+	xprv           string
+	vanityPrefix   string
 }
 
 // ============================================================================
@@ -266,21 +304,48 @@ worker is a goroutine that continuously generates Bitcoin addresses and checks f
 
 Parameters:
   - id: Worker thread identifier (for logging)
-  - wg: WaitGroup for coordinating shutdown (currently runs indefinitely)
-  - btcAddresses: Hash map of target addresses to search for
+  - ctx: Cancelled on SIGINT/SIGTERM, -max-duration, or -max-attempts (see
+    main); worker finishes its in-flight generation and returns instead of
+    starting another
+  - wg: WaitGroup for coordinating shutdown
+  - btcAddresses: AddressSet of target addresses to search for (Bloom-filtered
+    or plain map, depending on how main() built it)
+  - formatsPresent: per-network AddressFormatsPresent detected from the
+    loaded file (see detectPresentFormats); nil derives every format
+  - balanceCandidates: if non-nil (see -balance-check), every generated
+    candidate is also offered to the live balance/tx-history checker
+    (sources/balance), independently of whether it matched btcAddresses;
+    send is non-blocking so a slow or rate-limited API never stalls
+    generation
   - matchChan: Channel to send matches to the writer goroutine
-  - counter: Shared atomic counter for statistics tracking
+  - updates: Batched attempt counts are pushed here instead of bumping a
+    shared counter directly, so the Bruteforcer's speed monitor (see
+    bruteforcer.go) can aggregate throughput off the hot path entirely
+  - stream: non-nil only when -checkpoint is set (see searchcheckpoint.go);
+    switches key generation from crypto/rand to a deterministic
+    seed+id+offset stream so a resume can continue it instead of reseeding
+  - progress: where this worker's current WorkerProgress is published for
+    checkpointSearch to persist; nil whenever stream is nil
+
+Tracing:
+  - Registers itself as a runtime/trace task ("worker") and wraps each
+    iteration's phases in their own region - "generate" (private key),
+    "hash" (Hash160 + every network/format's address encoding), "lookup"
+    (btcAddresses.Contains), "write" (matchChan/balanceCandidates sends) -
+    so a -trace capture (see main) shows where an iteration's time actually
+    goes and where it blocks on a channel, instead of just aggregate CPU use
 
 Algorithm:
- 1. Generate random private key and address
+ 1. Generate a private key and address: from crypto/rand normally, or the
+    next position in stream's deterministic sequence when resumable
  2. Check if address exists in target database (O(1) hash map lookup)
  3. If match found, send to matchWriter via channel
- 4. Update global counter periodically (batch updates for performance)
- 5. Repeat indefinitely
+ 4. Push the attempt count periodically (batched, see updates above),
+    publishing WorkerProgress at the same cadence when stream is set
+ 5. Repeat until ctx is cancelled
 
 Performance Optimizations:
-  - Local counter: Batches atomic operations (10,000 keys per update)
-  - Atomic operations are expensive (CPU cache synchronization)
+  - Local counter: Batches channel sends (10,000 keys per update)
   - Batching reduces contention and improves throughput
   - Non-blocking match sending: Channel has buffer to prevent blocking
   - Continue on error: Rare errors don't stop the worker
@@ -288,51 +353,177 @@ Performance Optimizations:
 Concurrency Model:
   - Multiple workers run in parallel (typically numCPUs or numCPUs*2)
   - Each worker operates independently with its own RNG state
-  - Shared state: btcAddresses (read-only), counter (atomic), matchChan (buffered)
+  - Shared state: btcAddresses (read-only), updates (buffered), matchChan (buffered)
 
 Statistics:
   - Batch size: 10,000 keys (updateInterval)
   - Atomic updates reduce contention by 10,000x compared to updating every iteration
   - Typical throughput: 10,000-50,000 keys/sec per core (CPU-dependent)
 */
-func worker(id int, wg *sync.WaitGroup, btcAddresses map[string]bool, matchChan chan<- MatchResult, counter *uint64) {
-	defer wg.Done() // Signal completion when function returns (never in this case)
+func worker(ctx context.Context, id int, wg *sync.WaitGroup, btcAddresses AddressSet, networks []ChainParams, formatsPresent map[string]*AddressFormatsPresent, balanceCandidates chan<- balance.Candidate, matchChan chan<- MatchResult, updates chan<- uint64, stream *RandomStream, progress *atomic.Value) {
+	defer wg.Done() // Signal completion when function returns
+
+	// taskCtx carries this worker as a runtime/trace task for the rest of
+	// its lifetime; every region below (see -trace) shows up nested under
+	// it in `go tool trace`, so per-phase time and contention on matchChan
+	// and the shared counter/updates channel is visible per worker.
+	taskCtx, task := trace.NewTask(ctx, "worker")
+	defer task.End()
 
-	// Local counter for batching atomic updates
+	// Local counter for batching update pushes
 	localCounter := uint64(0)
-	const updateInterval = 10000 // Update global counter every 10k iterations
+	const updateInterval = 10000 // Push a batched update every 10k iterations
+
+	// offset/matchesWritten only matter when stream is set; they become this
+	// worker's next published WorkerProgress every updateInterval iterations.
+	var offset uint64
+	var matchesWritten uint64
+	if stream != nil {
+		offset = stream.StartOffset
+	}
 
-	// Infinite loop: continuously generate and check addresses
-	for {
-		// Generate new random private key and corresponding address
-		privateKey, publicAddress, err := generateKeyAndAddress()
-		if err != nil {
+	// Loop until ctx is cancelled, continuously generating and checking addresses
+	for ctx.Err() == nil {
+		// Generate one key pair. Normally crypto/rand via
+		// btcec.NewPrivateKey; with -checkpoint set,
+		// deterministically from stream's seed+id+offset instead, so a
+		// resume can pick the sequence back up from a saved offset.
+		var privateKey *btcec.PrivateKey
+		var pubKey *btcec.PublicKey
+		var genErr error
+		trace.WithRegion(taskCtx, "generate", func() {
+			if stream != nil {
+				scalar := deriveStreamScalar(stream.Seed, id, offset)
+				privateKey, pubKey = btcec.PrivKeyFromBytes(scalar)
+				offset++
+			} else {
+				var err error
+				privateKey, err = btcec.NewPrivateKey()
+				if err != nil {
+					genErr = err
+					return
+				}
+				pubKey = privateKey.PubKey()
+			}
+		})
+		if genErr != nil {
 			// This should be extremely rare (only if RNG fails)
-			log.Printf("Worker %d: Failed to generate key and address: %s", id, err)
+			log.Printf("Worker %d: Failed to generate key: %s", id, genErr)
 			continue // Skip this iteration and try again
 		}
 
 		// Increment local counter
 		localCounter++
 
-		// Batch update: Only update global counter every 10,000 iterations
-		// This reduces expensive atomic operations and cache synchronization
+		// Batch update: push to the monitor every 10,000 iterations instead
+		// of touching shared state every attempt
 		if localCounter%updateInterval == 0 {
-			atomic.AddUint64(counter, updateInterval) // Thread-safe increment
-			localCounter = 0                          // Reset local counter
+			updates <- updateInterval
+			localCounter = 0 // Reset local counter
+			if progress != nil {
+				progress.Store(WorkerProgress{Offset: offset, Attempts: offset, MatchesWritten: matchesWritten})
+			}
 		}
 
-		// Check if generated address exists in target database
-		// Hash map lookup is O(1) - constant time regardless of database size
-		if _, exists := btcAddresses[publicAddress]; exists {
-			// *** MATCH FOUND! ***
-			// This is an extremely rare event (probability: 1 in 2^160 per address)
-			fmt.Printf("\n*** MATCH FOUND! ***\nAddress: %s\n\n", publicAddress)
-
-			// Send match to writer goroutine via buffered channel
-			// Non-blocking if buffer has space
-			matchChan <- MatchResult{privateKey: privateKey, address: publicAddress}
+		// Derive Hash160 and, from it, every derived format on every
+		// enabled network - all the hashing/encoding work for one key.
+		var candidates []struct {
+			address string
+			format  string
+		}
+		trace.WithRegion(taskCtx, "hash", func() {
+			hash160 := btcutil.Hash160(pubKey.SerializeCompressed())
+			for _, network := range networks {
+				addrs, err := generateChainAddresses(network, pubKey, hash160, formatsFor(formatsPresent, network.Name))
+				if err != nil {
+					log.Printf("Worker %d: Failed to derive %s addresses: %s", id, network.Name, err)
+					continue
+				}
+				if addrs.CashAddr != "" {
+					candidates = append(candidates, struct {
+						address string
+						format  string
+					}{addrs.CashAddr, network.Name + ":CashAddr"})
+					continue
+				}
+				if addrs.Legacy != "" {
+					candidates = append(candidates, struct {
+						address string
+						format  string
+					}{addrs.Legacy, network.Name + ":P2PKH"})
+				}
+				if addrs.P2SHSegWit != "" {
+					candidates = append(candidates, struct {
+						address string
+						format  string
+					}{addrs.P2SHSegWit, network.Name + ":P2SH-P2WPKH"})
+				}
+				if addrs.Bech32 != "" {
+					candidates = append(candidates, struct {
+						address string
+						format  string
+					}{addrs.Bech32, network.Name + ":P2WPKH"})
+				}
+				if addrs.Taproot != "" {
+					candidates = append(candidates, struct {
+						address string
+						format  string
+					}{addrs.Taproot, network.Name + ":P2TR"})
+				}
+			}
+		})
+
+		// Check every derived format against the target database in one
+		// pass. Hash map lookup is O(1) - constant time regardless of
+		// database size.
+		var hits []struct {
+			address string
+			format  string
 		}
+		trace.WithRegion(taskCtx, "lookup", func() {
+			for _, candidate := range candidates {
+				if btcAddresses.Contains(candidate.address) {
+					hits = append(hits, candidate)
+				}
+			}
+		})
+
+		var privKeyHex string
+		if balanceCandidates != nil {
+			privKeyHex = hex.EncodeToString(privateKey.Serialize())
+		}
+		trace.WithRegion(taskCtx, "write", func() {
+			for _, hit := range hits {
+				// *** MATCH FOUND! ***
+				// This is an extremely rare event (probability: 1 in 2^160 per address)
+				fmt.Printf("\n*** MATCH FOUND! ***\nAddress: %s (%s)\n\n", hit.address, hit.format)
+
+				// Send match to writer goroutine via buffered channel
+				// Non-blocking if buffer has space
+				matchChan <- MatchResult{privateKey: privateKey, address: hit.address, format: hit.format}
+				matchesWritten++
+			}
+
+			// Offer every candidate (matched or not) to the live balance
+			// checker, dropping it instead of blocking if the checker's
+			// queue is backed up - a generated key is never worth stalling
+			// the hot path over.
+			if balanceCandidates != nil {
+				for _, candidate := range candidates {
+					select {
+					case balanceCandidates <- balance.Candidate{Address: candidate.address, PrivateKeyHex: privKeyHex, Format: candidate.format}:
+					default:
+					}
+				}
+			}
+		})
+	}
+
+	// Publish the final position once more before exiting, so a checkpoint
+	// taken right as ctx is cancelled still reflects this worker's last
+	// completed iteration rather than a stale interval-old one.
+	if progress != nil {
+		progress.Store(WorkerProgress{Offset: offset, Attempts: offset, MatchesWritten: matchesWritten})
 	}
 }
 
@@ -344,8 +535,14 @@ func worker(id int, wg *sync.WaitGroup, btcAddresses map[string]bool, matchChan
 matchWriter is a dedicated goroutine that writes found matches to a file.
 
 Parameters:
+  - ctx: Only used to log that a shutdown was requested; matchWriter keeps
+    draining matchChan regardless, since it's closed only after every worker
+    has actually returned (see main), so nothing queued is ever dropped
   - matchChan: Receive-only channel for MatchResult structs from workers
   - outputFile: Path to output file for saving matches
+  - passphrase: Passphrase each match record is encrypted under (see keystore.go)
+  - matchCount: Shared atomic counter incremented per match written, for the
+    exit summary
   - wg: WaitGroup to signal completion when channel closes
 
 Architecture:
@@ -356,15 +553,19 @@ Architecture:
 
 Output Format:
 
-	Each line: <private_key_hex>:<bitcoin_address>
-	Example: 5HpHagT65TZzG1PH3CSu63k8DbpvD8s5ip4nEB3kEsreAnchuDf:1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa
+	One JSON keystoreRecord per line. AddressPlaintext stays readable so the
+	file can still be grepped for a hit; everything else is AES-256-CTR
+	encrypted under a passphrase-derived key, protecting the same
+	"<private_key_hex>:<bitcoin_address>:<format>[:<mnemonic>:<derivation_path>:<xprv>][:vanity=<prefix>]"
+	line this used to write in plaintext (see keystore.go and the `decrypt`
+	subcommand, which reverses this).
 
 File Operations:
   - Opens file in append mode (preserves existing matches)
   - Creates file if doesn't exist
   - Sets permissions to 0644 (owner: rw, group/others: r)
   - Uses buffered writer for efficient disk I/O
-  - Flushes after each write to prevent data loss
+  - Flushes, then fsyncs, after each write to prevent data loss
 
 Performance:
   - Buffered I/O: Reduces system calls
@@ -376,7 +577,7 @@ Error Handling:
   - Log error if individual write fails, but continue processing
   - Graceful shutdown when channel is closed
 */
-func matchWriter(matchChan <-chan MatchResult, outputFile string, wg *sync.WaitGroup) {
+func matchWriter(ctx context.Context, matchChan <-chan MatchResult, outputFile string, passphrase string, matchCount *uint64, wg *sync.WaitGroup) {
 	defer wg.Done() // Signal completion when function returns
 
 	// Open output file with append mode (creates if doesn't exist)
@@ -387,8 +588,24 @@ func matchWriter(matchChan <-chan MatchResult, outputFile string, wg *sync.WaitG
 	}
 	defer file.Close() // Ensure file is closed on exit
 
-	// Use buffered writer for efficient disk I/O (reduces system calls)
+	// Log shutdown requests, but keep draining matchChan regardless: it's
+	// only closed once every worker has actually returned, so there's never
+	// a queued match left stranded by this.
+	stopLogging := make(chan struct{})
+	defer close(stopLogging)
+	go func() {
+		select {
+		case <-ctx.Done():
+			log.Printf("matchWriter: shutdown requested, flushing any remaining matches...")
+		case <-stopLogging:
+		}
+	}()
+
+	// Use buffered writer for efficient disk I/O (reduces system calls); Sync
+	// (registered before Flush, so it runs after) fsyncs the flushed bytes to
+	// disk so a match survives even a killed process.
 	writer := bufio.NewWriter(file)
+	defer file.Sync()
 	defer writer.Flush() // Ensure all buffered data is written on exit
 
 	// Process matches as they arrive via channel
@@ -399,91 +616,49 @@ func matchWriter(matchChan <-chan MatchResult, outputFile string, wg *sync.WaitG
 		privKeyBytes := match.privateKey.Serialize()
 		privKeyHex := hex.EncodeToString(privKeyBytes)
 
-		// Write to file in format: <privkey_hex>:<address>
-		if _, err := writer.WriteString(fmt.Sprintf("%s:%s\n", privKeyHex, match.address)); err != nil {
+		// Build the same line this used to write unencrypted: <privkey_hex>:
+		// <address>:<format>, plus <mnemonic>:<derivation_path>:<xprv> for
+		// -mode hd matches so the whole wallet can be reconstructed, not
+		// just the one matching key.
+		line := fmt.Sprintf("%s:%s:%s", privKeyHex, match.address, match.format)
+		if match.mnemonic != "" {
+			line = fmt.Sprintf("%s:%s:%s:%s", line, match.mnemonic, match.derivationPath, match.xprv)
+		}
+		if match.vanityPrefix != "" {
+			line = fmt.Sprintf("%s:vanity=%s", line, match.vanityPrefix)
+		}
+
+		record, err := encryptMatchRecord(passphrase, match.address, line)
+		if err != nil {
+			log.Printf("Failed to encrypt match for %s: %s", match.address, err)
+			continue
+		}
+		encoded, err := json.Marshal(record)
+		if err != nil {
+			log.Printf("Failed to encode match record for %s: %s", match.address, err)
+			continue
+		}
+		if _, err := writer.Write(append(encoded, '\n')); err != nil {
 			log.Printf("Failed to write match to file: %s", err)
 		}
 
-		// Flush immediately to ensure data is saved (important for rare matches)
+		// Flush and fsync immediately to ensure data is saved (important for rare matches)
 		writer.Flush()
+		file.Sync()
+		atomic.AddUint64(matchCount, 1)
 
 		// Also print to console for immediate visibility
-		fmt.Printf("SAVED TO FILE: %s:%s\n\n", privKeyHex, match.address)
+		fmt.Printf("SAVED TO FILE: %s:%s:%s\n\n", privKeyHex, match.address, match.format)
 	}
 }
 
 // ============================================================================
 // STATISTICS: Real-time Performance Monitoring
 // ============================================================================
-
-/*
-statsReporter is a goroutine that periodically displays performance statistics.
-
-Parameters:
-  - counter: Pointer to shared atomic counter (total keys generated across all workers)
-  - startTime: Program start time for calculating overall runtime
-
-Output:
-
-	Prints statistics every 10 seconds:
-	- Total keys generated since start
-	- Overall rate: Average keys/sec since program started
-	- Current rate: Instantaneous keys/sec (last 10 seconds)
-	- Runtime: Total elapsed time in seconds
-
-Metrics Explained:
-  - Total: Cumulative count of all generated addresses
-  - Overall Rate: total / elapsed_time (smoothed average)
-  - Current Rate: interval_keys / interval_time (real-time performance)
-  - Runtime: Time since program started
-
-Performance Analysis:
-  - Current rate higher than overall: Performance improving (CPU warming up)
-  - Current rate lower than overall: Performance degrading (thermal throttling, contention)
-  - Current rate fluctuating: Normal due to OS scheduling, GC pauses, etc.
-
-Typical Performance:
-  - Modern CPU (2020+): 20,000-50,000 keys/sec per core
-  - Total throughput: rate * num_workers
-  - Example: 8 cores × 30,000 keys/sec = 240,000 keys/sec total
-
-Thread Safety:
-  - Uses atomic.LoadUint64() for thread-safe counter reading
-  - No locks required (read-only access to shared counter)
-*/
-func statsReporter(counter *uint64, startTime time.Time) {
-	// Create ticker that fires every 10 seconds
-	ticker := time.NewTicker(10 * time.Second)
-	defer ticker.Stop() // Clean up ticker when function returns
-
-	// Track previous values for calculating instantaneous rate
-	lastTotal := uint64(0)
-	lastTime := startTime
-
-	// Wait for ticker events (every 10 seconds)
-	for range ticker.C {
-		// Read current counter value (thread-safe atomic operation)
-		total := atomic.LoadUint64(counter)
-		now := time.Now()
-
-		// Calculate overall statistics (since program start)
-		elapsed := time.Since(startTime).Seconds()
-		overallRate := float64(total) / elapsed
-
-		// Calculate instantaneous rate (last 10 seconds only)
-		intervalKeys := total - lastTotal           // Keys generated in last interval
-		intervalTime := now.Sub(lastTime).Seconds() // Time elapsed in last interval
-		instantRate := float64(intervalKeys) / intervalTime
-
-		// Display statistics
-		fmt.Printf("[Stats] Total: %d | Overall: %.0f keys/sec | Current: %.0f keys/sec | Runtime: %.0fs\n",
-			total, overallRate, instantRate, elapsed)
-
-		// Update tracking variables for next iteration
-		lastTotal = total
-		lastTime = now
-	}
-}
+//
+// Speed reporting and the worker pool itself are now owned by Bruteforcer
+// (see bruteforcer.go), which replaces the old fixed-size pool + standalone
+// statsReporter goroutine with a resizable pool and its own speed monitor.
 
 // ============================================================================
 // MAIN: Program Entry Point and Orchestration
@@ -498,9 +673,12 @@ Program Flow:
  3. Load target address database into memory
  4. Initialize shared data structures (counter, channels, waitgroups)
  5. Start matchWriter goroutine (file I/O)
- 6. Start statsReporter goroutine (monitoring)
- 7. Start worker pool goroutines (brute force)
- 8. Wait for completion (runs indefinitely until interrupted)
+ 6. Construct a Bruteforcer, which starts its speed-monitor goroutine
+ 7. Size the worker pool via Bruteforcer.SetNumWorkers (brute force)
+ 8. Wait for completion: either SIGINT/SIGTERM, -max-duration, or
+    -max-attempts cancels ctx and every worker drains its in-flight
+    generation and returns; -mode range can also finish on its own once the
+    whole keyspace slice is scanned. Either way, print an exit summary.
 
 Command-line Arguments:
  1. threads: Number of worker goroutines (typically numCPUs or numCPUs*2)
@@ -545,26 +723,103 @@ Performance Tuning:
   - Monitor "Current rate" in stats to find sweet spot
 */
 func main() {
+	// ========================================================================
+	// SUBCOMMAND DISPATCH
+	// ========================================================================
+
+	// `import` builds an on-disk AddressDB from a text address dump ahead of
+	// time, so a future run can pass -db instead of loading everything into
+	// RAM. It has its own tiny argument contract and exits immediately.
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		runImportCommand(os.Args[2:])
+		return
+	}
+
+	// `decrypt` reverses the encrypted keystore matchWriter writes matches
+	// to (see keystore.go), printing the original plaintext line for each
+	// record once its passphrase-derived MAC checks out.
+	if len(os.Args) > 1 && os.Args[1] == "decrypt" {
+		runDecryptCommand(os.Args[2:])
+		return
+	}
+
 	// ========================================================================
 	// ARGUMENT PARSING AND VALIDATION
 	// ========================================================================
 
-	// Check if correct number of arguments provided
-	if len(os.Args) != 4 {
-		fmt.Println("Usage: ./bitcoin-wallet-bruteforce-offline <threads> <output-file.txt> <btc-address-file.txt>")
+	filterFPR := flag.Float64("filter-fpr", 1e-7, "target false-positive rate for the address Bloom filter prefilter (0 disables the filter)")
+	dbPath := flag.String("db", "", "path to an on-disk AddressDB built with the 'import' subcommand (overrides btc-address-file.txt)")
+	networksFlag := flag.String("networks", "btc", "comma-separated chains to check each generated key against: btc, btc-testnet, ltc, doge, bch (ignored with -db, which is Bitcoin-mainnet only)")
+	mode := flag.String("mode", "random", "key search mode: 'random' (independent keys), 'hd' (BIP-39/BIP-32 wallet search, see -hd-paths/-hd-gap/-mnemonic-length), 'range' (deterministic keyspace scan, see -range-start/-range-end/-checkpoint), or 'vanity' (prefix search, see -vanity-prefixes/-vanity-case-insensitive)")
+	hdPathsFlag := flag.String("hd-paths", "m/44'/0'/0'/0/0..N,m/49'/0'/0'/0/0..N,m/84'/0'/0'/0/0..N,m/86'/0'/0'/0/0..N", "comma-separated BIP-32 derivation path templates to scan in -mode hd (the trailing \"start..N\" component is the address_index range; N is a placeholder, the count comes from -hd-gap)")
+	hdGap := flag.Int("hd-gap", 20, "address_index values to scan per derivation path in -mode hd")
+	mnemonicLength := flag.Int("mnemonic-length", 12, "BIP-39 mnemonic word count in -mode hd: 12, 15, 18, 21, or 24")
+	blockbookWS := flag.String("blockbook-ws", "", "wss:// URL of a Blockbook-compatible websocket endpoint to stream newly-seen addresses from and merge into the target set (incompatible with -db)")
+	blockbookCap := flag.Int("blockbook-cap", 2_000_000, "maximum addresses held in the LRU target set fed by -blockbook-ws")
+	balanceCheck := flag.Bool("balance-check", false, "also check every generated address's live balance/tx history via -balance-api, independently of -btc-address-file.txt (mode 'random' only)")
+	balanceAPI := flag.String("balance-api", balance.DefaultEndpoint, "URL template for the balance API, with a single %s for the pipe-joined batch of addresses (blockchain.info-compatible response shape)")
+	balanceBatchSize := flag.Int("balance-batch-size", balance.DefaultBatchSize, "addresses per -balance-check API request")
+	balanceInterval := flag.Duration("balance-interval", balance.DefaultDebounce, "longest a partial -balance-check batch waits before being sent anyway")
+	rangeStart := flag.String("range-start", "", "hex-encoded inclusive start of the keyspace to scan in -mode range (e.g. the start of a Bitcoin puzzle transaction's bit range)")
+	rangeEnd := flag.String("range-end", "", "hex-encoded exclusive end of the keyspace to scan in -mode range")
+	checkpointPath := flag.String("checkpoint", "", "file to periodically save/resume progress from: -mode range ({range_id: next_key} JSON) or -mode random ({seed, address_list_hash, workers} JSON, not supported with -db); disabled if empty")
+	maxDuration := flag.Duration("max-duration", 0, "stop gracefully after this long, e.g. \"2h\" (0 = run until interrupted)")
+	maxAttempts := flag.Uint64("max-attempts", 0, "stop gracefully after this many total attempts across all workers (0 = unlimited)")
+	vanityPrefixesFlag := flag.String("vanity-prefixes", "", "comma-separated address prefixes to search for in -mode vanity, e.g. \"1Love,1Cafe\"")
+	vanityCaseInsensitive := flag.Bool("vanity-case-insensitive", false, "ignore case when matching -vanity-prefixes in -mode vanity")
+	traceFile := flag.String("trace", "", "write a runtime/trace execution trace covering the worker pool's lifetime to this file (view with 'go tool trace'); disabled if empty")
+	pprofAddr := flag.String("pprof", "", "serve net/http/pprof profiling endpoints (e.g. /debug/pprof/profile) on this address, e.g. \":6060\"; disabled if empty")
+
+	flag.Usage = func() {
+		fmt.Println("Usage: ./bitcoin-wallet-bruteforce-offline [flags] <threads> <output-file.txt> [btc-address-file.txt]")
+		fmt.Println("       ./bitcoin-wallet-bruteforce-offline import <btc-address-file.txt> <db-file>")
+		fmt.Println("       ./bitcoin-wallet-bruteforce-offline decrypt <matches-file>")
 		fmt.Println()
 		fmt.Println("Arguments:")
 		fmt.Println("  threads            - Number of worker threads (recommend: num CPU cores)")
-		fmt.Println("  output-file.txt    - Output file for saving matches")
-		fmt.Println("  btc-address-file.txt - Input file with target Bitcoin addresses")
+		fmt.Println("  output-file.txt    - Output file for saving matches (written as an encrypted keystore, see 'decrypt')")
+		fmt.Println("  btc-address-file.txt - Input file with target Bitcoin addresses (omit when -db is set)")
+		fmt.Println()
+		fmt.Println("Flags:")
+		flag.PrintDefaults()
 		fmt.Println()
 		fmt.Println("Example:")
 		fmt.Println("  ./bitcoin-wallet-bruteforce-offline 8 matches.txt attack-addresses-p2pkh.txt")
+		fmt.Println("  ./bitcoin-wallet-bruteforce-offline import attack-addresses-p2pkh.txt targets.db")
+		fmt.Println("  ./bitcoin-wallet-bruteforce-offline -db targets.db 8 matches.txt")
+		fmt.Println("  ./bitcoin-wallet-bruteforce-offline -networks btc,ltc,doge,bch 8 matches.txt attack-addresses-p2pkh.txt")
+		fmt.Println("  ./bitcoin-wallet-bruteforce-offline -blockbook-ws wss://blockbook.example.com/websocket 8 matches.txt")
+		fmt.Println("  ./bitcoin-wallet-bruteforce-offline -max-duration 2h -max-attempts 100000000000 8 matches.txt attack-addresses-p2pkh.txt")
+		fmt.Println("  ./bitcoin-wallet-bruteforce-offline -balance-check 8 matches.txt")
+		fmt.Println("  ./bitcoin-wallet-bruteforce-offline -mode vanity -vanity-prefixes 1Love,1Cafe 8 matches.txt")
+		fmt.Println("  ./bitcoin-wallet-bruteforce-offline -checkpoint progress.json 8 matches.txt attack-addresses-p2pkh.txt")
+		fmt.Println("  ./bitcoin-wallet-bruteforce-offline -trace trace.out -pprof :6060 8 matches.txt attack-addresses-p2pkh.txt")
+	}
+	flag.Parse()
+
+	if *dbPath != "" && *blockbookWS != "" {
+		log.Fatalf("-db and -blockbook-ws are mutually exclusive: AddressDB is keyed by raw bytes, the live feed needs a mutable address set")
+	}
+
+	// Check if correct number of positional arguments provided. The target
+	// address file is always required with -db (exactly 2 args: threads,
+	// output). Otherwise it's optional when -blockbook-ws, -balance-check,
+	// or -mode vanity supplies (or supplements) a way to recognize a hit,
+	// and required otherwise (exactly 3 args).
+	minArgs, maxArgs := 3, 3
+	switch {
+	case *dbPath != "":
+		minArgs, maxArgs = 2, 2
+	case *blockbookWS != "" || *balanceCheck || *mode == "vanity":
+		minArgs, maxArgs = 2, 3
+	}
+	if flag.NArg() < minArgs || flag.NArg() > maxArgs {
+		flag.Usage()
 		os.Exit(1)
 	}
 
 	// Parse number of worker threads
-	numThreads, err := strconv.Atoi(os.Args[1])
+	numThreads, err := strconv.Atoi(flag.Arg(0))
 	if err != nil {
 		log.Fatalf("Invalid number of threads: %s", err)
 	}
@@ -574,6 +829,67 @@ func main() {
 		log.Fatalf("Number of threads must be at least 1")
 	}
 
+	networks, err := parseNetworks(*networksFlag)
+	if err != nil {
+		log.Fatalf("Invalid -networks: %s", err)
+	}
+
+	var hdPaths []*DerivationPath
+	var keyRanges []*KeyRange
+	var vanityTarget *VanityTarget
+	switch *mode {
+	case "hd":
+		hdPaths, err = parseHDPaths(*hdPathsFlag)
+		if err != nil {
+			log.Fatalf("Invalid -hd-paths: %s", err)
+		}
+		if _, ok := mnemonicEntropyBits[*mnemonicLength]; !ok {
+			log.Fatalf("Invalid -mnemonic-length %d: must be one of 12, 15, 18, 21, 24", *mnemonicLength)
+		}
+	case "range":
+		start, ok := new(big.Int).SetString(*rangeStart, 16)
+		if !ok {
+			log.Fatalf("Invalid -range-start %q: must be hex", *rangeStart)
+		}
+		end, ok := new(big.Int).SetString(*rangeEnd, 16)
+		if !ok {
+			log.Fatalf("Invalid -range-end %q: must be hex", *rangeEnd)
+		}
+		keyRanges, err = parseKeyRanges(start, end, numThreads)
+		if err != nil {
+			log.Fatalf("Invalid -mode range bounds: %s", err)
+		}
+		if *checkpointPath != "" {
+			saved, err := loadRangeCheckpoint(*checkpointPath)
+			if err != nil {
+				log.Fatalf("Invalid -checkpoint: %s", err)
+			}
+			for _, kr := range keyRanges {
+				if next, ok := saved[kr.ID]; ok {
+					resumed, ok := new(big.Int).SetString(next, 16)
+					if !ok {
+						log.Fatalf("Checkpoint %s: malformed saved position %q for %s", *checkpointPath, next, kr.ID)
+					}
+					kr.Start = resumed
+				}
+			}
+		}
+	case "vanity":
+		prefixes, err := parseVanityPrefixes(*vanityPrefixesFlag, *vanityCaseInsensitive)
+		if err != nil {
+			log.Fatalf("Invalid -vanity-prefixes: %s", err)
+		}
+		vanityTarget = &VanityTarget{Prefixes: prefixes, CaseInsensitive: *vanityCaseInsensitive}
+	case "random":
+		// No extra validation.
+	default:
+		log.Fatalf("Invalid -mode %q: must be 'random', 'hd', 'range', or 'vanity'", *mode)
+	}
+
+	if *balanceCheck && *mode != "random" {
+		log.Fatalf("-balance-check is only supported with -mode random")
+	}
+
 	// ========================================================================
 	// RUNTIME CONFIGURATION
 	// ========================================================================
@@ -582,6 +898,37 @@ func main() {
 	// GOMAXPROCS controls how many OS threads can execute Go code simultaneously
 	runtime.GOMAXPROCS(runtime.NumCPU())
 
+	// -pprof serves net/http/pprof's handlers (registered on
+	// http.DefaultServeMux by this file's blank import) so HPS plateaus can
+	// be diagnosed live with `go tool pprof` while a scan is running.
+	if *pprofAddr != "" {
+		go func() {
+			log.Printf("pprof: serving profiling endpoints on %s", *pprofAddr)
+			if err := http.ListenAndServe(*pprofAddr, nil); err != nil {
+				log.Printf("pprof: server stopped: %s", err)
+			}
+		}()
+	}
+
+	// -trace wraps the worker pool's lifetime in a runtime/trace trace;
+	// worker() annotates itself as a task with "generate"/"hash"/"lookup"/
+	// "write" regions (see worker), so `go tool trace` shows per-phase time
+	// and channel contention instead of just overall CPU usage.
+	var stopTrace func()
+	if *traceFile != "" {
+		f, err := os.Create(*traceFile)
+		if err != nil {
+			log.Fatalf("Failed to create -trace file: %s", err)
+		}
+		if err := trace.Start(f); err != nil {
+			log.Fatalf("Failed to start trace: %s", err)
+		}
+		stopTrace = func() {
+			trace.Stop()
+			f.Close()
+		}
+	}
+
 	// ========================================================================
 	// BANNER AND SYSTEM INFORMATION
 	// ========================================================================
@@ -592,25 +939,137 @@ func main() {
 	fmt.Printf("CPU Cores: %d | Worker Threads: %d\n", runtime.NumCPU(), numThreads)
 	fmt.Printf("SHA256: Hardware Accelerated (SIMD)\n")
 	fmt.Printf("Public Key: Compressed (33 bytes)\n")
-	fmt.Printf("Address Type: Legacy P2PKH (starts with '1')\n\n")
+	fmt.Printf("Address Types: P2PKH ('1...'), P2SH-P2WPKH ('3...'), P2WPKH ('bc1q...'), P2TR ('bc1p...')\n")
+	if *mode == "hd" {
+		fmt.Printf("Mode: HD wallet search (%d-word mnemonics, gap %d, paths: %s)\n", *mnemonicLength, *hdGap, *hdPathsFlag)
+	}
+	if *mode == "range" {
+		fmt.Printf("Mode: Puzzle range scan [%s, %s) across %d workers", *rangeStart, *rangeEnd, numThreads)
+		if *checkpointPath != "" {
+			fmt.Printf(", checkpointing to %s", *checkpointPath)
+		}
+		fmt.Printf("\n")
+	}
+	if *mode == "vanity" {
+		fmt.Printf("Mode: Vanity prefix search (prefixes: %s, case-insensitive: %t)\n", *vanityPrefixesFlag, *vanityCaseInsensitive)
+	}
+	if *dbPath == "" {
+		names := make([]string, len(networks))
+		for i, n := range networks {
+			names[i] = n.Name
+		}
+		fmt.Printf("Networks: %s\n", strings.Join(names, ", "))
+	}
+	fmt.Printf("\n")
 
 	// ========================================================================
 	// FILE ARGUMENT EXTRACTION
 	// ========================================================================
 
-	outputFile := os.Args[2]       // Where to save matches
-	btcAddressesFile := os.Args[3] // Database of target addresses
+	outputFile := flag.Arg(1) // Where to save matches
 
 	// ========================================================================
 	// ADDRESS DATABASE LOADING
 	// ========================================================================
 
-	fmt.Printf("Loading addresses from %s...\n", btcAddressesFile)
-	btcAddresses, err := readAddresses(btcAddressesFile)
-	if err != nil {
-		log.Fatalf("Failed to read BTC addresses: %s", err)
+	// Two mutually exclusive backends: -db opens a pre-built, memory-mapped
+	// on-disk AddressDB (see addressdb.go); otherwise the legacy path loads
+	// the whole address file into a map, optionally Bloom-filtered.
+	var addressDB AddressDB
+	var btcAddresses AddressSet
+
+	// formatsPresent stays nil (meaning "derive everything") unless a static
+	// file is loaded below: a live -blockbook-ws feed or an empty set can't
+	// be trusted to represent the format mix the search should cover.
+	var formatsPresent map[string]*AddressFormatsPresent
+
+	// rawAddresses is also used below to fingerprint the target list for
+	// -checkpoint (see searchcheckpoint.go); stays nil when -db is used.
+	var rawAddresses map[string]bool
+
+	if *dbPath != "" {
+		if *checkpointPath != "" && *mode == "random" {
+			log.Fatalf("-checkpoint is not supported together with -db")
+		}
+		fmt.Printf("Opening address database %s...\n", *dbPath)
+		db, err := openBoltAddressDB(*dbPath)
+		if err != nil {
+			log.Fatalf("Failed to open address db: %s", err)
+		}
+		defer db.Close()
+		addressDB = db
+		fmt.Printf("✓ Address database ready\n\n")
+	} else {
+		rawAddresses = map[string]bool{}
+		if flag.NArg() == 3 {
+			btcAddressesFile := flag.Arg(2)
+			fmt.Printf("Loading addresses from %s...\n", btcAddressesFile)
+			rawAddresses, err = readAddresses(btcAddressesFile)
+			if err != nil {
+				log.Fatalf("Failed to read BTC addresses: %s", err)
+			}
+			fmt.Printf("✓ Loaded %d addresses to check against\n\n", len(rawAddresses))
+			if *blockbookWS == "" && len(rawAddresses) > 0 {
+				formatsPresent = detectPresentFormats(rawAddresses, networks)
+			}
+		}
+
+		if *blockbookWS != "" {
+			liveSet := newLRUAddressSet(*blockbookCap, rawAddresses)
+			btcAddresses = liveSet
+
+			feedCtx, cancelFeed := context.WithCancel(context.Background())
+			defer cancelFeed()
+			client := &blockbook.Client{URL: *blockbookWS, OnAddress: liveSet.Add}
+			go client.Run(feedCtx)
+			fmt.Printf("✓ Streaming addresses from %s (LRU cap %d)\n\n", *blockbookWS, *blockbookCap)
+		} else {
+			btcAddresses = newAddressSet(rawAddresses, *filterFPR)
+		}
+	}
+
+	// -checkpoint with -mode random: load (or start) the deterministic
+	// stream seed and each worker's resume offset, validating that any
+	// saved state was produced against this same target address list.
+	var randomStreams []*RandomStream
+	var searchProgress map[string]*atomic.Value
+	var randomSeed [32]byte
+	if *mode == "random" && *checkpointPath != "" {
+		addrListHash := hashAddressList(rawAddresses)
+		saved, err := loadSearchCheckpoint(*checkpointPath)
+		if err != nil {
+			log.Fatalf("Invalid -checkpoint: %s", err)
+		}
+		if saved != nil && saved.AddressListHash != addrListHash {
+			log.Fatalf("-checkpoint %s was saved against a different target address list; refusing to resume", *checkpointPath)
+		}
+		if saved != nil {
+			seedBytes, err := hex.DecodeString(saved.Seed)
+			if err != nil || len(seedBytes) != len(randomSeed) {
+				log.Fatalf("-checkpoint %s: malformed seed", *checkpointPath)
+			}
+			copy(randomSeed[:], seedBytes)
+		} else if _, err := rand.Read(randomSeed[:]); err != nil {
+			log.Fatalf("Failed to generate a checkpoint seed: %s", err)
+		}
+
+		randomStreams = make([]*RandomStream, numThreads)
+		searchProgress = make(map[string]*atomic.Value, numThreads)
+		for i := 0; i < numThreads; i++ {
+			workerID := fmt.Sprintf("worker-%d", i)
+			var startOffset uint64
+			if saved != nil {
+				startOffset = saved.Workers[workerID].Offset
+			}
+			randomStreams[i] = &RandomStream{Seed: randomSeed, StartOffset: startOffset}
+			searchProgress[workerID] = &atomic.Value{}
+		}
+		if saved != nil {
+			fmt.Printf("✓ Resuming from checkpoint %s\n\n", *checkpointPath)
+		} else {
+			fmt.Printf("✓ Checkpointing to %s\n\n", *checkpointPath)
+		}
 	}
-	fmt.Printf("✓ Loaded %d addresses to check against\n\n", len(btcAddresses))
 
 	// ========================================================================
 	// SHARED STATE INITIALIZATION
@@ -619,13 +1078,123 @@ func main() {
 	// Atomic counter for total keys generated (shared across all workers)
 	var counter uint64
 
+	// Atomic counter for matches actually written, for the exit summary
+	var matchCount uint64
+
 	// Buffered channel for sending matches from workers to file writer
 	// Buffer size: 100 (prevents blocking if matches found in bursts)
 	matchChan := make(chan MatchResult, 100)
 
 	// WaitGroups for coordinating goroutine shutdown
-	var workerWg sync.WaitGroup // Tracks worker goroutines
-	var writerWg sync.WaitGroup // Tracks writer goroutine
+	var writerWg sync.WaitGroup     // Tracks writer goroutine
+	var checkpointWg sync.WaitGroup // Tracks the -mode range / -checkpoint goroutine
+	var balanceWg sync.WaitGroup    // Tracks the -balance-check goroutine
+
+	// ctx is cancelled by SIGINT/SIGTERM, -max-duration, or -max-attempts;
+	// every worker, the speed monitor, and the checkpointers all
+	// watch it so a shutdown finishes in-flight work and saves progress
+	// instead of killing the process mid-write.
+	ctx, cancel := context.WithCancel(context.Background())
+	if *maxDuration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, *maxDuration)
+	}
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-sigCh:
+			fmt.Printf("\nReceived %s, shutting down gracefully (finishing in-flight work)...\n", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if *maxAttempts > 0 {
+		go func() {
+			ticker := time.NewTicker(250 * time.Millisecond)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if atomic.LoadUint64(&counter) >= *maxAttempts {
+						fmt.Printf("\nReached -max-attempts=%d, shutting down gracefully...\n", *maxAttempts)
+						cancel()
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	// rangeCursors holds each -mode range worker's current position, so
+	// checkpointRanges can persist it periodically without the worker
+	// itself touching disk I/O.
+	var rangeCursors map[string]*atomic.Value
+	if *mode == "range" {
+		rangeCursors = make(map[string]*atomic.Value, len(keyRanges))
+		for _, kr := range keyRanges {
+			cursor := &atomic.Value{}
+			cursor.Store(kr.Start.Text(16))
+			rangeCursors[kr.ID] = cursor
+		}
+		if *checkpointPath != "" {
+			checkpointWg.Add(1)
+			go func() {
+				defer checkpointWg.Done()
+				checkpointRanges(*checkpointPath, rangeCursors, 10*time.Second, ctx.Done())
+			}()
+		}
+	}
+
+	// searchProgress (see above) is non-nil only for -mode random with
+	// -checkpoint set; checkpointSearch persists it the same way
+	// checkpointRanges does for -mode range.
+	if searchProgress != nil {
+		checkpointWg.Add(1)
+		go func() {
+			defer checkpointWg.Done()
+			checkpointSearch(*checkpointPath, randomSeed, hashAddressList(rawAddresses), searchProgress, 10*time.Second, ctx.Done())
+		}()
+	}
+
+	// balanceCandidates feeds every generated candidate (not just local
+	// matches) to a sources/balance.Checker when -balance-check is set, so
+	// an address funded in the wild - never in any local wordlist - still
+	// gets flagged. Its queue is deliberately small: worker()'s send is
+	// non-blocking, so a backed-up checker just drops candidates rather
+	// than ever stalling generation.
+	var balanceCandidates chan balance.Candidate
+	if *balanceCheck {
+		balanceCandidates = make(chan balance.Candidate, 1000)
+		checker := &balance.Checker{Endpoint: *balanceAPI, BatchSize: *balanceBatchSize, Debounce: *balanceInterval}
+		balanceWg.Add(1)
+		go func() {
+			defer balanceWg.Done()
+			checker.Run(ctx, balanceCandidates, func(m balance.Match) {
+				keyBytes, err := hex.DecodeString(m.PrivateKeyHex)
+				if err != nil {
+					log.Printf("balance-check: malformed private key for %s: %s", m.Address, err)
+					return
+				}
+				privateKey, _ := btcec.PrivKeyFromBytes(keyBytes)
+				fmt.Printf("\n*** LIVE BALANCE MATCH! ***\nAddress: %s (%s)\nBalance: %d satoshis | Tx count: %d\n\n", m.Address, m.Format, m.FinalBalance, m.NTx)
+				matchChan <- MatchResult{privateKey: privateKey, address: m.Address, format: fmt.Sprintf("%s [balance-check]", m.Format)}
+			})
+		}()
+		fmt.Printf("✓ Live balance checking enabled (%s, batch %d, debounce %s)\n\n", *balanceAPI, *balanceBatchSize, *balanceInterval)
+	}
+
+	// Any match is encrypted at rest under this passphrase (see
+	// keystore.go); prompted for up front so a match found hours into an
+	// unattended run doesn't block on input that never arrives.
+	passphrase, err := promptPassphrase("Set a passphrase to encrypt any matches found (recover them with the 'decrypt' subcommand): ")
+	if err != nil {
+		log.Fatalf("Failed to read passphrase: %s", err)
+	}
 
 	// ========================================================================
 	// GOROUTINE STARTUP
@@ -633,34 +1202,93 @@ func main() {
 
 	// Start match writer goroutine (handles file I/O asynchronously)
 	writerWg.Add(1)
-	go matchWriter(matchChan, outputFile, &writerWg)
+	go matchWriter(ctx, matchChan, outputFile, passphrase, &matchCount, &writerWg)
+
+	// updates lets worker() (the default "random" mode) report batched key
+	// counts to the Bruteforcer's monitor instead of bumping counter itself;
+	// every other mode's worker still bumps counter directly, which the
+	// monitor picks up by polling it each tick.
+	updates := make(chan uint64, 1024)
+
+	// spawn dispatches to the mode-specific worker function for slot id,
+	// wrapping it in a throwaway WaitGroup since each worker function still
+	// expects one (Bruteforcer tracks completion itself via spawn returning).
+	spawn := func(ctx context.Context, id int) {
+		var wg sync.WaitGroup
+		wg.Add(1)
+		switch {
+		case addressDB != nil:
+			go workerDB(ctx, id, &wg, addressDB, matchChan, &counter)
+		case *mode == "hd":
+			go workerHD(ctx, id, &wg, btcAddresses, networks, formatsPresent, hdPaths, *hdGap, *mnemonicLength, matchChan, &counter)
+		case *mode == "range":
+			kr := keyRanges[id]
+			go rangeWorker(ctx, id, &wg, btcAddresses, networks, formatsPresent, kr, rangeCursors[kr.ID], matchChan, &counter)
+		case *mode == "vanity":
+			go vanityWorker(ctx, id, &wg, vanityTarget, networks, formatsPresent, matchChan, &counter)
+		default:
+			var stream *RandomStream
+			var progress *atomic.Value
+			if randomStreams != nil {
+				stream = randomStreams[id]
+				progress = searchProgress[fmt.Sprintf("worker-%d", id)]
+			}
+			go worker(ctx, id, &wg, btcAddresses, networks, formatsPresent, balanceCandidates, matchChan, updates, stream, progress)
+		}
+		wg.Wait()
+	}
 
-	// Start stats reporter goroutine (displays performance metrics)
+	// Start the worker pool and its speed monitor (displays performance
+	// metrics every 10 seconds, same as the old statsReporter).
 	startTime := time.Now()
-	go statsReporter(&counter, startTime)
+	bruteforcer := NewBruteforcer(ctx, &counter, updates, startTime, spawn)
 
-	// Start worker pool (brute force address generation)
 	fmt.Printf("Starting brute force...\n")
 	fmt.Printf("════════════════════════════════════════════════════════════\n\n")
-	for i := 0; i < numThreads; i++ {
-		workerWg.Add(1)
-		go worker(i, &workerWg, btcAddresses, matchChan, &counter)
-	}
+	bruteforcer.SetNumWorkers(numThreads)
 
 	// ========================================================================
 	// MAIN LOOP (BLOCKING)
 	// ========================================================================
 
-	// Wait for all workers to complete (never happens in current implementation)
-	// Workers run indefinitely until program is interrupted (Ctrl+C)
-	workerWg.Wait()
+	// Wait for every worker to return: on ctx cancellation (signal,
+	// -max-duration, or -max-attempts) each finishes its in-flight
+	// generation and stops; for -mode range this also happens on its own
+	// once every worker's slice of the keyspace is exhausted.
+	bruteforcer.Wait()
 
-	// Close match channel to signal writer to finish
-	close(matchChan)
+	// Stop tracing now, while it still covers exactly the worker pool's
+	// lifetime, rather than leaving it open until the deferred cancel below.
+	if stopTrace != nil {
+		stopTrace()
+	}
 
-	// Wait for writer to finish processing remaining matches
+	// Cancel ctx unconditionally (a no-op if already cancelled) so the range
+	// checkpointer, speed monitor, and watchdog goroutines above wind down
+	// immediately rather than lingering until the deferred cancel on return.
+	cancel()
+	checkpointWg.Wait()
+
+	// Close the balance checker's input (if running) and wait for it to
+	// return; ctx is already cancelled here, so its final flush gives up on
+	// a still-pending batch rather than querying it. It may still write to
+	// matchChan, so this must happen before matchChan is closed below.
+	if balanceCandidates != nil {
+		close(balanceCandidates)
+		balanceWg.Wait()
+	}
+
+	// Close match channel to signal writer to finish, then wait for it to
+	// flush (and fsync) everything still queued.
+	close(matchChan)
 	writerWg.Wait()
 
-	// Note: Program typically runs until manually interrupted
-	// To implement graceful shutdown, add signal handling (SIGINT, SIGTERM)
+	elapsed := time.Since(startTime)
+	totalAttempts := atomic.LoadUint64(&counter)
+	fmt.Printf("\n════════════════════════════════════════════════════════════\n")
+	fmt.Printf("Exit summary:\n")
+	fmt.Printf("  Attempts: %d\n", totalAttempts)
+	fmt.Printf("  Elapsed:  %s\n", elapsed.Round(time.Second))
+	fmt.Printf("  Matches:  %d\n", atomic.LoadUint64(&matchCount))
+	fmt.Printf("  Average rate: %.0f keys/sec\n", float64(totalAttempts)/elapsed.Seconds())
 }
@@ -0,0 +1,136 @@
+// Package cashaddr implements Bitcoin Cash's CashAddr encoding
+// (https://github.com/bitcoincashorg/bitcoincash.org/blob/master/spec/cashaddr.md),
+// used instead of Base58Check for BCH addresses since the 2018 UAHF. It is a
+// different checksum scheme from Bech32 (a distinct polynomial and a
+// self-inverse XOR constant of 1), even though it reuses Bech32's 5-bit
+// charset.
+package cashaddr
+
+import (
+	"fmt"
+	"strings"
+)
+
+const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// Hash type, encoded in the top bits of the version byte.
+const (
+	HashTypePubKey byte = 0
+	HashTypeScript byte = 1
+)
+
+// polymod computes the CashAddr checksum polynomial over a slice of 5-bit
+// values, per the reference implementation in the CashAddr specification.
+func polymod(values []byte) uint64 {
+	c := uint64(1)
+	for _, d := range values {
+		c0 := c >> 35
+		c = (c&0x07ffffffff)<<5 ^ uint64(d)
+		if c0&0x01 != 0 {
+			c ^= 0x98f2bc8e61
+		}
+		if c0&0x02 != 0 {
+			c ^= 0x79b76d99e2
+		}
+		if c0&0x04 != 0 {
+			c ^= 0xf33e5fb3c4
+		}
+		if c0&0x08 != 0 {
+			c ^= 0xae2eabe2a8
+		}
+		if c0&0x10 != 0 {
+			c ^= 0x1e4f43e470
+		}
+	}
+	return c ^ 1
+}
+
+// prefixExpand implements the CashAddr prefix expansion: the lower 5 bits of
+// each prefix character, followed by a zero separator.
+func prefixExpand(prefix string) []byte {
+	out := make([]byte, 0, len(prefix)+1)
+	for i := 0; i < len(prefix); i++ {
+		out = append(out, prefix[i]&0x1f)
+	}
+	return append(out, 0)
+}
+
+// convertBits re-packs a byte slice from fromBits-wide groups to toBits-wide
+// groups, used to turn the version+hash payload into 5-bit groups.
+func convertBits(data []byte, fromBits, toBits uint, pad bool) []byte {
+	acc, bits := uint32(0), uint(0)
+	maxVal := uint32(1)<<toBits - 1
+	var out []byte
+	for _, b := range data {
+		acc = acc<<fromBits | uint32(b)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte(acc>>bits)&byte(maxVal))
+		}
+	}
+	if pad && bits > 0 {
+		out = append(out, byte(acc<<(toBits-bits))&byte(maxVal))
+	}
+	return out
+}
+
+// versionByte encodes the hash type and size per the CashAddr spec's size
+// table. Only the lengths Bitcoin actually produces (20-byte Hash160 and
+// 32-byte script hash) are supported.
+func versionByte(hashType byte, hashLen int) (byte, error) {
+	var sizeBits byte
+	switch hashLen {
+	case 20:
+		sizeBits = 0
+	case 32:
+		sizeBits = 3
+	default:
+		return 0, fmt.Errorf("cashaddr: unsupported hash length %d", hashLen)
+	}
+	return hashType<<3 | sizeBits, nil
+}
+
+// Encode builds a full "prefix:payload" CashAddr string for the given hash
+// type (pubkey or script hash) and raw hash bytes.
+func Encode(prefix string, hashType byte, hash []byte) (string, error) {
+	ver, err := versionByte(hashType, len(hash))
+	if err != nil {
+		return "", err
+	}
+
+	payload := append([]byte{ver}, hash...)
+	data5bit := convertBits(payload, 8, 5, true)
+
+	checksumInput := append(prefixExpand(prefix), data5bit...)
+	checksumInput = append(checksumInput, make([]byte, 8)...)
+	mod := polymod(checksumInput)
+
+	checksum := make([]byte, 8)
+	for i := 0; i < 8; i++ {
+		checksum[i] = byte((mod >> uint(5*(7-i))) & 31)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	sb.WriteByte(':')
+	for _, b := range data5bit {
+		sb.WriteByte(charset[b])
+	}
+	for _, b := range checksum {
+		sb.WriteByte(charset[b])
+	}
+	return sb.String(), nil
+}
+
+// EncodeP2PKH encodes a 20-byte Hash160 as a CashAddr pay-to-pubkey-hash
+// address, e.g. "bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvn0h829pq".
+func EncodeP2PKH(prefix string, hash160 []byte) (string, error) {
+	return Encode(prefix, HashTypePubKey, hash160)
+}
+
+// EncodeP2SH encodes a 20-byte script hash as a CashAddr pay-to-script-hash
+// address.
+func EncodeP2SH(prefix string, hash160 []byte) (string, error) {
+	return Encode(prefix, HashTypeScript, hash160)
+}
@@ -0,0 +1,272 @@
+package main
+
+/*
+Multi-Chain Address Generation
+
+generateAllAddresses is hard-coded to Bitcoin mainnet: version byte 0x00 for
+P2PKH, HRP "bc" for Bech32/Bech32m. A secp256k1 key pair is just as valid on
+several other chains that reuse Bitcoin's address formats with different
+version bytes/HRPs (Litecoin, Dogecoin) or a different encoding entirely
+(Bitcoin Cash's CashAddr, see the cashaddr subpackage). ChainParams captures
+what differs per chain so one generated key can be checked against every
+enabled network in a single pass, selected with -networks.
+*/
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/base58"
+
+	"github.com/Asylian21/btc-brute-force/addr"
+	"github.com/Asylian21/btc-brute-force/cashaddr"
+)
+
+// ChainParams describes the address formats a given chain derives from a
+// secp256k1 key pair.
+type ChainParams struct {
+	Name string
+
+	P2PKHVersion byte // Base58Check version byte for P2PKH
+	P2SHVersion  byte // Base58Check version byte for P2SH
+
+	Bech32HRP       string // "" disables native SegWit/Taproot for this chain
+	SupportsTaproot bool
+
+	CashAddrPrefix string // "" disables CashAddr; set for Bitcoin Cash
+}
+
+// chainParamPresets holds the built-in networks selectable via -networks.
+var chainParamPresets = map[string]ChainParams{
+	"btc": {
+		Name:            "Bitcoin",
+		P2PKHVersion:    0x00,
+		P2SHVersion:     0x05,
+		Bech32HRP:       "bc",
+		SupportsTaproot: true,
+	},
+	"btc-testnet": {
+		Name:            "Bitcoin Testnet",
+		P2PKHVersion:    0x6f,
+		P2SHVersion:     0xc4,
+		Bech32HRP:       "tb",
+		SupportsTaproot: true,
+	},
+	"ltc": {
+		Name:         "Litecoin",
+		P2PKHVersion: 0x30,
+		P2SHVersion:  0x32,
+		Bech32HRP:    "ltc",
+	},
+	"doge": {
+		Name:         "Dogecoin",
+		P2PKHVersion: 0x1e,
+		P2SHVersion:  0x16,
+		// Dogecoin mainnet has no widely deployed SegWit, so Bech32HRP is
+		// left empty: only the legacy P2PKH/P2SH formats are generated.
+	},
+	"bch": {
+		Name:           "Bitcoin Cash",
+		CashAddrPrefix: "bitcoincash",
+	},
+}
+
+// parseNetworks turns a comma-separated -networks flag value into the list
+// of ChainParams to check every generated key against.
+func parseNetworks(flagValue string) ([]ChainParams, error) {
+	if flagValue == "" {
+		return []ChainParams{chainParamPresets["btc"]}, nil
+	}
+
+	var params []ChainParams
+	for _, name := range strings.Split(flagValue, ",") {
+		name = strings.TrimSpace(strings.ToLower(name))
+		preset, ok := chainParamPresets[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown network %q (known: btc, btc-testnet, ltc, doge, bch)", name)
+		}
+		params = append(params, preset)
+	}
+	return params, nil
+}
+
+// ChainAddresses holds the address formats one ChainParams produces for a
+// single key pair.
+type ChainAddresses struct {
+	Chain      string
+	Legacy     string
+	P2SHSegWit string // "" if the chain doesn't do SegWit
+	Bech32     string // "" if the chain doesn't do SegWit
+	Taproot    string // "" if the chain doesn't support Taproot
+	CashAddr   string // "" unless the chain uses CashAddr instead
+}
+
+// generateChainAddresses derives the address formats a given ChainParams
+// produces from one already-generated key, reusing the pubkey/hash160 work
+// done once regardless of how many networks are enabled. Only the formats
+// enabled in wanted are computed; a ChainAddresses field is left at its
+// zero value ("") for anything not wanted, same as for a format the chain
+// doesn't support at all. Pass allFormatsPresent to derive everything.
+func generateChainAddresses(params ChainParams, pubKey *btcec.PublicKey, hash160 []byte, wanted AddressFormatsPresent) (*ChainAddresses, error) {
+	out := &ChainAddresses{Chain: params.Name}
+
+	if params.CashAddrPrefix != "" {
+		if !wanted.CashAddr {
+			return out, nil
+		}
+		addr, err := cashaddr.EncodeP2PKH(params.CashAddrPrefix, hash160)
+		if err != nil {
+			return nil, err
+		}
+		out.CashAddr = addr
+		return out, nil
+	}
+
+	if wanted.Legacy {
+		legacy, err := base58CheckEncode(params.P2PKHVersion, hash160)
+		if err != nil {
+			return nil, err
+		}
+		out.Legacy = legacy
+	}
+
+	if wanted.P2SHSegWit {
+		p2sh, err := base58CheckEncodeP2SHSegwit(params.P2SHVersion, hash160)
+		if err != nil {
+			return nil, err
+		}
+		out.P2SHSegWit = p2sh
+	}
+
+	if params.Bech32HRP != "" {
+		if wanted.Bech32 {
+			bech32Addr, err := addr.EncodeSegwit(params.Bech32HRP, 0, hash160)
+			if err != nil {
+				return nil, err
+			}
+			out.Bech32 = bech32Addr
+		}
+
+		if params.SupportsTaproot && wanted.Taproot {
+			taproot, err := taprootAddressForHRP(params.Bech32HRP, pubKey)
+			if err != nil {
+				return nil, err
+			}
+			out.Taproot = taproot
+		}
+	}
+
+	return out, nil
+}
+
+// AddressFormatsPresent records which of a network's address formats were
+// actually observed in a loaded target file, so worker()/workerHD() can skip
+// deriving and checking formats that the file provably does not contain.
+type AddressFormatsPresent struct {
+	Legacy, P2SHSegWit, Bech32, Taproot, CashAddr bool
+}
+
+// detectPresentFormats scans a loaded address set once and, for each enabled
+// network, determines which address formats actually occur in it by
+// decoding every address's Base58Check version byte or Bech32 witness
+// version. Real-world dumps are overwhelmingly single-format (e.g. an
+// all-Bech32 leak), so skipping the formats a dump provably lacks avoids
+// deriving and encoding three candidate addresses per key for nothing.
+//
+// CashAddr is left enabled unconditionally: its bare form (no
+// "bitcoincash:" prefix) is indistinguishable from Bech32 by charset alone,
+// so detection would be unreliable, and getting this wrong would silently
+// drop real targets rather than just cost a little CPU.
+func detectPresentFormats(addresses map[string]bool, networks []ChainParams) map[string]*AddressFormatsPresent {
+	present := make(map[string]*AddressFormatsPresent, len(networks))
+	for _, network := range networks {
+		present[network.Name] = &AddressFormatsPresent{CashAddr: network.CashAddrPrefix != ""}
+	}
+
+	for address := range addresses {
+		if _, version, err := base58.CheckDecode(address); err == nil {
+			for _, network := range networks {
+				fmts := present[network.Name]
+				if version == network.P2PKHVersion {
+					fmts.Legacy = true
+				}
+				if version == network.P2SHVersion {
+					fmts.P2SHSegWit = true
+				}
+			}
+			continue
+		}
+
+		sep := strings.LastIndexByte(address, '1')
+		if sep <= 0 {
+			continue
+		}
+		witnessVersion, _, err := addr.DecodeSegwit(address)
+		if err != nil {
+			continue
+		}
+		hrp := address[:sep]
+		for _, network := range networks {
+			if network.Bech32HRP == "" || network.Bech32HRP != hrp {
+				continue
+			}
+			fmts := present[network.Name]
+			switch witnessVersion {
+			case 0:
+				fmts.Bech32 = true
+			case 1:
+				if network.SupportsTaproot {
+					fmts.Taproot = true
+				}
+			}
+		}
+	}
+
+	return present
+}
+
+// allFormatsPresent is the "optimization disabled" fallback: every format is
+// assumed present, so generateChainAddresses derives every format.
+var allFormatsPresent = AddressFormatsPresent{Legacy: true, P2SHSegWit: true, Bech32: true, Taproot: true, CashAddr: true}
+
+// formatsFor looks up the detected format presence for a network, falling
+// back to allFormatsPresent when detection was skipped (formatsPresent is
+// nil, e.g. no static file was loaded) or the network has no entry.
+func formatsFor(formatsPresent map[string]*AddressFormatsPresent, networkName string) AddressFormatsPresent {
+	if formatsPresent == nil {
+		return allFormatsPresent
+	}
+	if fmts, ok := formatsPresent[networkName]; ok {
+		return *fmts
+	}
+	return allFormatsPresent
+}
+
+// base58CheckEncode Base58Check-encodes a Hash160 under an arbitrary P2PKH
+// version byte, generalizing generateLegacyAddress beyond Bitcoin mainnet.
+func base58CheckEncode(version byte, hash160 []byte) (string, error) {
+	return base58.CheckEncode(hash160, version), nil
+}
+
+// base58CheckEncodeP2SHSegwit wraps a P2WPKH witness program in a P2SH
+// output under an arbitrary P2SH version byte, generalizing
+// generateP2SHWPKH beyond Bitcoin mainnet.
+func base58CheckEncodeP2SHSegwit(version byte, hash160 []byte) (string, error) {
+	redeemScript := make([]byte, 0, 22)
+	redeemScript = append(redeemScript, 0x00, 0x14)
+	redeemScript = append(redeemScript, hash160...)
+	scriptHash := btcutil.Hash160(redeemScript)
+	return base58.CheckEncode(scriptHash, version), nil
+}
+
+// taprootAddressForHRP is generateP2TR generalized to an arbitrary HRP
+// (Bitcoin mainnet uses "bc"; testnet would use "tb").
+func taprootAddressForHRP(hrp string, pub *btcec.PublicKey) (string, error) {
+	xonly, err := addr.TaprootOutputKey(pub)
+	if err != nil {
+		return "", err
+	}
+	return addr.EncodeSegwit(hrp, 1, xonly[:])
+}
@@ -0,0 +1,104 @@
+// Package hd implements BIP-32 master-key and child-key derivation from
+// scratch, independent of hdwallet.go's btcutil/hdkeychain-backed -mode hd.
+// hdkeychain is the right choice for the CLI's real wallet-recovery mode
+// (see hdwallet.go's doc comment), but the bench package needs a
+// dependency-free, allocation-lean derivation step to benchmark the
+// seed-to-address pipeline against weak-passphrase-derived wallets, the
+// same way addr gives it a from-scratch Bech32/Taproot encoder instead of
+// pulling in a newer btcutil.
+package hd
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+)
+
+// HardenedKeyStart is the first hardened child index per BIP-32: indexes
+// at or above this derive from the parent's private key rather than its
+// public key.
+const HardenedKeyStart = 0x80000000
+
+// ErrInvalidChild is returned by Child when the derived IL is >= the
+// curve order or the resulting child key is zero. BIP-32 notes this has
+// roughly a 1 in 2^127 chance per index; the prescribed recovery is to
+// retry derivation at the next index.
+var ErrInvalidChild = errors.New("hd: invalid child index, retry at i+1")
+
+// Key is a BIP-32 extended private key: a 32-byte secp256k1 scalar plus
+// its 32-byte chain code.
+type Key struct {
+	Scalar    [32]byte
+	ChainCode [32]byte
+}
+
+// NewMasterKey derives the BIP-32 master key from a raw seed (e.g. a
+// BIP-39 seed) via HMAC-SHA512 with the fixed key "Bitcoin seed": IL
+// becomes the master private key, IR the master chain code.
+func NewMasterKey(seed []byte) (*Key, error) {
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	var scalar btcec.ModNScalar
+	if overflow := scalar.SetByteSlice(sum[:32]); overflow || scalar.IsZero() {
+		return nil, ErrInvalidChild
+	}
+
+	k := &Key{}
+	scalar.PutBytesUnchecked(k.Scalar[:])
+	copy(k.ChainCode[:], sum[32:])
+	return k, nil
+}
+
+// PrivateKey returns k's scalar as a btcec/v2 private key, the input the
+// rest of the address-generation pipeline expects.
+func (k *Key) PrivateKey() *btcec.PrivateKey {
+	var scalar btcec.ModNScalar
+	scalar.SetByteSlice(k.Scalar[:])
+	return btcec.PrivKeyFromScalar(&scalar)
+}
+
+// Child derives k's i-th child per BIP-32: hardened (i >= HardenedKeyStart)
+// uses data = 0x00 || k || ser32(i); non-hardened uses data =
+// serP(K) || ser32(i), where serP(K) is k's compressed public key. IL is
+// added mod n to k's scalar to produce the child scalar; IR becomes the
+// child's chain code. Returns ErrInvalidChild if IL >= n or the resulting
+// child key is zero - per BIP-32, callers should then retry Child(i+1).
+func (k *Key) Child(i uint32) (*Key, error) {
+	var data []byte
+	if i >= HardenedKeyStart {
+		data = make([]byte, 0, 37)
+		data = append(data, 0x00)
+		data = append(data, k.Scalar[:]...)
+	} else {
+		data = append(make([]byte, 0, 37), k.PrivateKey().PubKey().SerializeCompressed()...)
+	}
+	var idx [4]byte
+	binary.BigEndian.PutUint32(idx[:], i)
+	data = append(data, idx[:]...)
+
+	mac := hmac.New(sha512.New, k.ChainCode[:])
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	var il btcec.ModNScalar
+	if overflow := il.SetByteSlice(sum[:32]); overflow {
+		return nil, ErrInvalidChild
+	}
+
+	var parent btcec.ModNScalar
+	parent.SetByteSlice(k.Scalar[:])
+	childScalar := il.Add(&parent)
+	if childScalar.IsZero() {
+		return nil, ErrInvalidChild
+	}
+
+	child := &Key{}
+	childScalar.PutBytesUnchecked(child.Scalar[:])
+	copy(child.ChainCode[:], sum[32:])
+	return child, nil
+}
@@ -0,0 +1,295 @@
+package main
+
+/*
+HD Wallet Search Mode (-mode hd)
+
+worker()/workerDB() each attempt is an independent random 256-bit scalar,
+which is how a key generated outside any wallet software looks. In
+practice virtually every real wallet instead derives its keys from a
+BIP-39 mnemonic through a BIP-32 hierarchy, walking one of a handful of
+well-known BIP-44/49/84/86 paths. workerHD mirrors that: generate a random
+mnemonic, derive its seed (PBKDF2-HMAC-SHA512, BIP-39), walk down to the
+BIP-32 account/change node for each configured path, then scan a gap of
+address indexes under it, checking every derived address against the
+target set exactly like worker() does.
+
+BIP-32 derivation itself is delegated to btcutil's hdkeychain package
+(already pulled in transitively via btcutil) rather than reimplemented,
+since unlike Bech32/Taproot in the addr package there's a directly usable,
+already-vendored implementation. hdkeychain.ExtendedKey.ECPrivKey()
+returns the older (non-v2) btcec.PrivateKey; deriveKeyAndHash160 converts its
+serialized bytes into a btcec/v2 key so the rest of the pipeline
+(generateAllAddresses-style encoding) can stay on v2 throughout.
+*/
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// mnemonicEntropyBits maps a requested mnemonic word count to the BIP-39
+// entropy size that produces it.
+var mnemonicEntropyBits = map[int]int{
+	12: 128,
+	15: 160,
+	18: 192,
+	21: 224,
+	24: 256,
+}
+
+// DerivationPath is a parsed `-hd-paths` template such as
+// "m/44'/0'/0'/0/0..N": Account holds every path component down to (and
+// including) the change level, already hardened where the template used
+// a trailing "'". GapStart is the address_index the scan starts from; the
+// scan covers GapStart..GapStart+gap-1, where gap comes from -hd-gap.
+type DerivationPath struct {
+	Account  []uint32
+	GapStart uint32
+}
+
+// parseDerivationPath parses one comma-separated element of -hd-paths.
+// The final path component is the address_index range and must look like
+// "<start>..N" (N is a placeholder; the actual count comes from -hd-gap)
+// or a bare "<start>".
+func parseDerivationPath(path string) (*DerivationPath, error) {
+	trimmed := strings.TrimPrefix(strings.TrimSpace(path), "m/")
+	if trimmed == "" {
+		return nil, fmt.Errorf("empty derivation path %q", path)
+	}
+	parts := strings.Split(trimmed, "/")
+
+	last := parts[len(parts)-1]
+	if idx := strings.Index(last, ".."); idx >= 0 {
+		last = last[:idx]
+	}
+	gapStart, err := strconv.ParseUint(last, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address_index range %q in path %q: %w", parts[len(parts)-1], path, err)
+	}
+
+	account := make([]uint32, 0, len(parts)-1)
+	for _, component := range parts[:len(parts)-1] {
+		index, err := parsePathComponent(component)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path component %q in path %q: %w", component, path, err)
+		}
+		account = append(account, index)
+	}
+
+	return &DerivationPath{Account: account, GapStart: uint32(gapStart)}, nil
+}
+
+// parsePathComponent parses a single BIP-32 path component, e.g. "44'" or
+// "0", applying hdkeychain.HardenedKeyStart for a trailing "'".
+func parsePathComponent(component string) (uint32, error) {
+	hardened := strings.HasSuffix(component, "'")
+	component = strings.TrimSuffix(component, "'")
+	n, err := strconv.ParseUint(component, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	index := uint32(n)
+	if hardened {
+		index += hdkeychain.HardenedKeyStart
+	}
+	return index, nil
+}
+
+// formatDerivationPath renders path's account components plus a concrete
+// addressIndex back into "m/44'/0'/0'/0/5" form, the inverse of
+// parseDerivationPath/parsePathComponent, for annotating a match with the
+// exact path that produced it.
+func formatDerivationPath(path *DerivationPath, addressIndex uint32) string {
+	components := make([]string, 0, len(path.Account)+1)
+	for _, index := range path.Account {
+		components = append(components, formatPathComponent(index))
+	}
+	components = append(components, formatPathComponent(addressIndex))
+	return "m/" + strings.Join(components, "/")
+}
+
+// formatPathComponent renders a single parsed path component, re-appending
+// the hardened marker "'" if index is at or past HardenedKeyStart.
+func formatPathComponent(index uint32) string {
+	if index >= hdkeychain.HardenedKeyStart {
+		return strconv.FormatUint(uint64(index-hdkeychain.HardenedKeyStart), 10) + "'"
+	}
+	return strconv.FormatUint(uint64(index), 10)
+}
+
+// parseHDPaths parses the comma-separated -hd-paths flag value.
+func parseHDPaths(flagValue string) ([]*DerivationPath, error) {
+	var paths []*DerivationPath
+	for _, template := range strings.Split(flagValue, ",") {
+		path, err := parseDerivationPath(template)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// deriveAccountKey walks a master key down through every fixed path
+// component (everything but the address_index), e.g. m/44'/0'/0'/0.
+func deriveAccountKey(master *hdkeychain.ExtendedKey, path *DerivationPath) (*hdkeychain.ExtendedKey, error) {
+	key := master
+	for _, index := range path.Account {
+		child, err := key.Child(index)
+		if err != nil {
+			return nil, err
+		}
+		key = child
+	}
+	return key, nil
+}
+
+// deriveKeyAndHash160 derives one address_index child beneath accountKey and
+// returns it in the btcec/v2 representation the rest of the address
+// generation pipeline uses, alongside the child's own serialized extended
+// key (xprv) so a match can be traced back to a reusable wallet node.
+func deriveKeyAndHash160(accountKey *hdkeychain.ExtendedKey, addressIndex uint32) (*btcec.PrivateKey, *btcec.PublicKey, []byte, string, error) {
+	child, err := accountKey.Child(addressIndex)
+	if err != nil {
+		return nil, nil, nil, "", err
+	}
+	oldPrivKey, err := child.ECPrivKey()
+	if err != nil {
+		return nil, nil, nil, "", err
+	}
+	privKey, pubKey := btcec.PrivKeyFromBytes(oldPrivKey.Serialize())
+	hash160 := btcutil.Hash160(pubKey.SerializeCompressed())
+	return privKey, pubKey, hash160, child.String(), nil
+}
+
+/*
+workerHD is the HD-wallet counterpart of worker(): instead of an
+independent random key per attempt, it generates a random BIP-39 mnemonic
+and scans a gap of address indexes under each configured derivation path,
+checking every derived key's full address family against the target set.
+*/
+func workerHD(ctx context.Context, id int, wg *sync.WaitGroup, btcAddresses AddressSet, networks []ChainParams, formatsPresent map[string]*AddressFormatsPresent, paths []*DerivationPath, gap int, mnemonicWords int, matchChan chan<- MatchResult, counter *uint64) {
+	defer wg.Done()
+
+	localCounter := uint64(0)
+	const updateInterval = 10000
+
+	entropyBits, ok := mnemonicEntropyBits[mnemonicWords]
+	if !ok {
+		log.Printf("Worker %d: unsupported mnemonic length %d", id, mnemonicWords)
+		return
+	}
+
+	for ctx.Err() == nil {
+		entropy, err := bip39.NewEntropy(entropyBits)
+		if err != nil {
+			log.Printf("Worker %d: Failed to generate mnemonic entropy: %s", id, err)
+			continue
+		}
+		mnemonic, err := bip39.NewMnemonic(entropy)
+		if err != nil {
+			log.Printf("Worker %d: Failed to build mnemonic: %s", id, err)
+			continue
+		}
+		seed := bip39.NewSeed(mnemonic, "")
+
+		master, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+		if err != nil {
+			log.Printf("Worker %d: Failed to derive master key: %s", id, err)
+			continue
+		}
+
+		for _, path := range paths {
+			accountKey, err := deriveAccountKey(master, path)
+			if err != nil {
+				log.Printf("Worker %d: Failed to derive account key: %s", id, err)
+				continue
+			}
+
+			for i := 0; i < gap; i++ {
+				addressIndex := path.GapStart + uint32(i)
+				privKey, pubKey, hash160, xprv, err := deriveKeyAndHash160(accountKey, addressIndex)
+				if err != nil {
+					log.Printf("Worker %d: Failed to derive address_index key: %s", id, err)
+					continue
+				}
+
+				localCounter++
+				if localCounter%updateInterval == 0 {
+					atomic.AddUint64(counter, updateInterval)
+					localCounter = 0
+				}
+
+				var candidates []struct {
+					address string
+					format  string
+				}
+				for _, network := range networks {
+					addrs, err := generateChainAddresses(network, pubKey, hash160, formatsFor(formatsPresent, network.Name))
+					if err != nil {
+						log.Printf("Worker %d: Failed to derive %s addresses: %s", id, network.Name, err)
+						continue
+					}
+					if addrs.CashAddr != "" {
+						candidates = append(candidates, struct {
+							address string
+							format  string
+						}{addrs.CashAddr, network.Name + ":CashAddr"})
+						continue
+					}
+					if addrs.Legacy != "" {
+						candidates = append(candidates, struct {
+							address string
+							format  string
+						}{addrs.Legacy, network.Name + ":P2PKH"})
+					}
+					if addrs.P2SHSegWit != "" {
+						candidates = append(candidates, struct {
+							address string
+							format  string
+						}{addrs.P2SHSegWit, network.Name + ":P2SH-P2WPKH"})
+					}
+					if addrs.Bech32 != "" {
+						candidates = append(candidates, struct {
+							address string
+							format  string
+						}{addrs.Bech32, network.Name + ":P2WPKH"})
+					}
+					if addrs.Taproot != "" {
+						candidates = append(candidates, struct {
+							address string
+							format  string
+						}{addrs.Taproot, network.Name + ":P2TR"})
+					}
+				}
+
+				for _, candidate := range candidates {
+					if !btcAddresses.Contains(candidate.address) {
+						continue
+					}
+					derivationPath := formatDerivationPath(path, addressIndex)
+					fmt.Printf("\n*** MATCH FOUND! ***\nAddress: %s (%s)\nMnemonic: %s\nPath: %s\n\n", candidate.address, candidate.format, mnemonic, derivationPath)
+					matchChan <- MatchResult{
+						privateKey:     privKey,
+						address:        candidate.address,
+						format:         candidate.format,
+						mnemonic:       mnemonic,
+						derivationPath: derivationPath,
+						xprv:           xprv,
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/tyler-smith/go-bip39"
+)
+
+func TestParseDerivationPath(t *testing.T) {
+	path, err := parseDerivationPath("m/44'/0'/0'/0/5..N")
+	if err != nil {
+		t.Fatalf("parseDerivationPath: %s", err)
+	}
+	want := []uint32{
+		44 + hdkeychain.HardenedKeyStart,
+		0 + hdkeychain.HardenedKeyStart,
+		0 + hdkeychain.HardenedKeyStart,
+		0,
+	}
+	if len(path.Account) != len(want) {
+		t.Fatalf("Account = %v, want %v", path.Account, want)
+	}
+	for i := range want {
+		if path.Account[i] != want[i] {
+			t.Errorf("Account[%d] = %d, want %d", i, path.Account[i], want[i])
+		}
+	}
+	if path.GapStart != 5 {
+		t.Errorf("GapStart = %d, want 5", path.GapStart)
+	}
+}
+
+// TestDeriveAccountKeyBIP32Vector1 derives m/0'/1/2' from BIP-32 test vector
+// 1's seed and checks it against the spec's published extended private key.
+func TestDeriveAccountKeyBIP32Vector1(t *testing.T) {
+	seed, err := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	if err != nil {
+		t.Fatalf("decoding seed: %s", err)
+	}
+	master, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("NewMaster: %s", err)
+	}
+
+	path, err := parseDerivationPath("m/0'/1/2'/0..N")
+	if err != nil {
+		t.Fatalf("parseDerivationPath: %s", err)
+	}
+	accountKey, err := deriveAccountKey(master, path)
+	if err != nil {
+		t.Fatalf("deriveAccountKey: %s", err)
+	}
+
+	const wantPriv = "xprv9z4pot5VBttmtdRTWfWQmoH1taj2axGVzFqSb8C9xaxKymcFzXBDptWmT7FwuEzG3ryjH4ktypQSAewRiNMjANTtpgP4mLTj34bhnZX7UiM"
+	if got := accountKey.String(); got != wantPriv {
+		t.Errorf("derived extended key = %s, want %s", got, wantPriv)
+	}
+}
+
+// TestBIP39SeedVector checks bip39.NewSeed against the standard BIP-39
+// all-zero-entropy test vector (passphrase "TREZOR").
+func TestBIP39SeedVector(t *testing.T) {
+	const mnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	const wantSeedHex = "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04"
+
+	seed := bip39.NewSeed(mnemonic, "TREZOR")
+	if got := hex.EncodeToString(seed); got != wantSeedHex {
+		t.Errorf("seed = %s, want %s", got, wantSeedHex)
+	}
+}
@@ -0,0 +1,111 @@
+// Package keygen provides a batched scalar-base-multiplication pipeline:
+// given many 32-byte scalars, it derives all of their compressed public
+// keys while sharing a single field inversion across the whole batch
+// (Montgomery's trick) instead of paying one inversion per key.
+//
+// btcec/v2 (the dcrec-derived rewrite) already keeps its own precomputed
+// comb/window table for the generator point inside ScalarBaseMultNonConst -
+// that's where the v2 migration's ~30% ScalarBaseMult / >50%
+// ParseCompressedPubKey gains over the old btcec come from. Batch's own
+// contribution on top of that is narrower: avoid the per-key
+// Jacobian-to-affine inversion and the per-key *PublicKey allocation that
+// btcec.NewPrivateKey/SerializeCompressed each pay for exactly one point.
+package keygen
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+)
+
+// Batch derives compressed public keys from scalars in bulk, reusing its
+// internal scratch slices across calls so repeatedly calling Generate at a
+// fixed batch size does not allocate. The zero value is ready to use.
+type Batch struct {
+	points    []btcec.JacobianPoint
+	zProducts []btcec.FieldVal
+}
+
+// Generate computes out[i] = SerializeCompressed(scalars[i]*G) for every i,
+// writing each 33-byte compressed key directly into out[i] with no
+// per-key allocation. len(out) must equal len(scalars).
+func (b *Batch) Generate(scalars [][]byte, out [][33]byte) error {
+	n := len(scalars)
+	if len(out) != n {
+		return fmt.Errorf("keygen: len(out)=%d != len(scalars)=%d", len(out), n)
+	}
+	if n == 0 {
+		return nil
+	}
+
+	if cap(b.points) < n {
+		b.points = make([]btcec.JacobianPoint, n)
+		b.zProducts = make([]btcec.FieldVal, n)
+	}
+	b.points = b.points[:n]
+	b.zProducts = b.zProducts[:n]
+
+	for i, s := range scalars {
+		var k btcec.ModNScalar
+		if overflow := k.SetByteSlice(s); overflow {
+			return fmt.Errorf("keygen: scalar %d is out of range", i)
+		}
+		btcec.ScalarBaseMultNonConst(&k, &b.points[i])
+	}
+
+	batchToAffine(b.points, b.zProducts)
+
+	for i := range b.points {
+		p := &b.points[i]
+		if p.Y.IsOdd() {
+			out[i][0] = 0x03
+		} else {
+			out[i][0] = 0x02
+		}
+		xBytes := p.X.Bytes()
+		copy(out[i][1:], xBytes[:])
+	}
+	return nil
+}
+
+// batchToAffine converts points from Jacobian to affine coordinates in
+// place, sharing one field inversion across the whole batch via
+// Montgomery's trick: compute the running product of every Z, invert that
+// single product, then walk back dividing out one Z at a time to recover
+// each point's individual Z^-1. scratch must have len(points) elements and
+// is used as working space; its contents are overwritten.
+//
+// Every point must have a non-zero Z (true for anything produced by
+// ScalarBaseMultNonConst on a valid scalar, since G is never the point at
+// infinity).
+func batchToAffine(points []btcec.JacobianPoint, scratch []btcec.FieldVal) {
+	n := len(points)
+
+	scratch[0].Set(&points[0].Z)
+	for i := 1; i < n; i++ {
+		scratch[i].Set(&scratch[i-1]).Mul(&points[i].Z)
+	}
+
+	var acc btcec.FieldVal
+	acc.Set(&scratch[n-1]).Inverse()
+
+	for i := n - 1; i > 0; i-- {
+		var zInv, origZ btcec.FieldVal
+		origZ.Set(&points[i].Z)
+		zInv.Set(&acc).Mul(&scratch[i-1])
+		applyZInv(&points[i], &zInv)
+		acc.Mul(&origZ)
+	}
+	applyZInv(&points[0], &acc)
+}
+
+// applyZInv normalizes p to affine form given zInv = p.Z^-1, matching
+// JacobianPoint.ToAffine's X = X/Z^2, Y = Y/Z^3.
+func applyZInv(p *btcec.JacobianPoint, zInv *btcec.FieldVal) {
+	var zInv2, zInv3 btcec.FieldVal
+	zInv2.SquareVal(zInv)
+	zInv3.Mul2(zInv, &zInv2)
+	p.X.Mul(&zInv2).Normalize()
+	p.Y.Mul(&zInv3).Normalize()
+	p.Z.SetInt(1)
+}
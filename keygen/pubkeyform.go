@@ -0,0 +1,69 @@
+package keygen
+
+/*
+Uncompressed PubKey Address Variants
+
+Batch.Generate always derives the 33-byte compressed serialization, the
+modern default. A nontrivial fraction of historical Bitcoin addresses -
+including several well-known puzzle/early-era targets - instead derive
+from the 65-byte uncompressed serialization (0x04 || X || Y). Both
+serializations come from the same curve point, so GenerateAddresses lets a
+caller cover both address surfaces per private key without paying for a
+second scalar multiplication: priv.PubKey() is computed once and reused
+for whichever serialization(s) form selects. The hybrid serialization
+(0x06/0x07 || X || Y) is not derived.
+*/
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcutil"
+)
+
+// PubKeyForm selects which pubkey serialization(s) GenerateAddresses
+// derives a Hash160 from.
+type PubKeyForm int
+
+const (
+	// Compressed derives from the 33-byte compressed serialization
+	// (0x02/0x03 || X), the modern default.
+	Compressed PubKeyForm = iota
+	// Uncompressed derives from the 65-byte uncompressed serialization
+	// (0x04 || X || Y), used by early-era and several well-known puzzle
+	// addresses.
+	Uncompressed
+	// Both derives one Hash160 per serialization, reusing the single
+	// PublicKey a Compressed- or Uncompressed-only call would compute
+	// anyway.
+	Both
+)
+
+// GenerateAddresses writes priv's Hash160(es) into out according to form:
+// one element for Compressed or Uncompressed, two (compressed first, then
+// uncompressed) for Both. len(out) must match form's element count.
+func GenerateAddresses(priv *btcec.PrivateKey, form PubKeyForm, out [][]byte) error {
+	pub := priv.PubKey()
+
+	switch form {
+	case Compressed:
+		if len(out) != 1 {
+			return fmt.Errorf("keygen: PubKeyForm Compressed needs len(out)=1, got %d", len(out))
+		}
+		out[0] = btcutil.Hash160(pub.SerializeCompressed())
+	case Uncompressed:
+		if len(out) != 1 {
+			return fmt.Errorf("keygen: PubKeyForm Uncompressed needs len(out)=1, got %d", len(out))
+		}
+		out[0] = btcutil.Hash160(pub.SerializeUncompressed())
+	case Both:
+		if len(out) != 2 {
+			return fmt.Errorf("keygen: PubKeyForm Both needs len(out)=2, got %d", len(out))
+		}
+		out[0] = btcutil.Hash160(pub.SerializeCompressed())
+		out[1] = btcutil.Hash160(pub.SerializeUncompressed())
+	default:
+		return fmt.Errorf("keygen: unknown PubKeyForm %d", form)
+	}
+	return nil
+}
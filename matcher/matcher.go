@@ -0,0 +1,190 @@
+// Package matcher is a two-tier target-address matcher keyed directly on
+// raw 20-byte Hash160/witness-program bytes, rather than on the decoded
+// address string the way addressset.go's AddressSet/BloomFilter are.
+// Operating on the raw bytes lets Matcher.Test run allocation-free in a
+// hash-generation hot loop that never has to format an address string
+// just to check it, the same hot-loop-first spirit as keygen.Batch.
+//
+// Like addressset.go, Test consults a probabilistic Bloom filter before
+// falling back to an exact lookup: a filter miss is a guaranteed miss and
+// skips the map entirely, while a (rare) filter hit falls through to the
+// map to rule out the filter's false positives.
+package matcher
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+	sha256simd "github.com/minio/sha256-simd"
+
+	"github.com/Asylian21/btc-brute-force/addr"
+)
+
+// Matcher answers set-membership queries for raw 20-byte target keys
+// (P2PKH/P2SH Hash160 or P2WPKH witness programs).
+type Matcher struct {
+	filter *bloomFilter
+	exact  map[[20]byte]struct{}
+}
+
+// New builds a Matcher over keys, sizing its Bloom filter for a
+// false-positive rate of p.
+func New(keys [][20]byte, p float64) *Matcher {
+	m := &Matcher{
+		filter: newBloomFilter(len(keys), p),
+		exact:  make(map[[20]byte]struct{}, len(keys)),
+	}
+	for _, key := range keys {
+		m.filter.add(key)
+		m.exact[key] = struct{}{}
+	}
+	return m
+}
+
+// Test reports whether hash160 is one of the loaded target keys. A Bloom
+// filter miss short-circuits to false without touching the exact map; a
+// filter hit falls through to the map to confirm it. Allocation-free.
+func (m *Matcher) Test(hash160 [20]byte) (hit bool) {
+	if !m.filter.mightContain(hash160) {
+		return false
+	}
+	_, hit = m.exact[hash160]
+	return hit
+}
+
+// LoadFile reads target addresses from a newline-delimited text file - one
+// Base58Check (P2PKH/P2SH) or Bech32 P2WPKH address per line - decoding
+// each once into its raw 20-byte key so Test never has to.
+func LoadFile(path string) ([][20]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var keys [][20]byte
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		key, err := decodeToKey(line)
+		if err != nil {
+			return nil, fmt.Errorf("matcher: %q: %w", line, err)
+		}
+		keys = append(keys, key)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// decodeToKey decodes a single text address into the 20-byte key it
+// should be matched by: the Hash160 for Base58Check P2PKH/P2SH, or the
+// witness program for a Bech32 P2WPKH address. P2TR's 32-byte x-only
+// output key isn't a 20-byte Hash160 and so isn't supported here.
+func decodeToKey(address string) ([20]byte, error) {
+	var key [20]byte
+
+	if strings.HasPrefix(address, "bc1") {
+		_, program, err := addr.DecodeSegwit(address)
+		if err != nil {
+			return key, err
+		}
+		if len(program) != 20 {
+			return key, fmt.Errorf("unsupported witness program length %d (only P2WPKH's 20-byte program is a Hash160)", len(program))
+		}
+		copy(key[:], program)
+		return key, nil
+	}
+
+	decoded, _, err := base58.CheckDecode(address)
+	if err != nil {
+		return key, err
+	}
+	if len(decoded) != 20 {
+		return key, fmt.Errorf("unexpected decoded payload length %d", len(decoded))
+	}
+	copy(key[:], decoded)
+	return key, nil
+}
+
+// bloomFilter is a fixed-size probabilistic set over [20]byte keys:
+// mightContain never returns a false negative, but may return a false
+// positive at roughly the configured rate. Bit indices are derived from a
+// single SHA-256 hash of the key, split into two 64-bit halves combined
+// via the Kirsch-Mitzenmacher double hashing technique (g_i = h1 + i*h2
+// mod m), avoiding k independent hashes - the same scheme as
+// addressset.go's string-keyed BloomFilter.
+type bloomFilter struct {
+	bits []uint64 // packed bitset, m bits total
+	m    uint64   // number of bits
+	k    uint     // number of hash functions
+}
+
+// newBloomFilter sizes a filter for n expected elements at false-positive
+// rate p:
+//
+//	m = ceil(-n * ln(p) / ln(2)^2)   (bits)
+//	k = round((m/n) * ln(2))         (hash functions)
+func newBloomFilter(n int, p float64) *bloomFilter {
+	if n < 1 {
+		n = 1
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	if m < 64 {
+		m = 64
+	}
+	k := uint(math.Round((float64(m) / float64(n)) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+
+	return &bloomFilter{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    k,
+	}
+}
+
+// hashPair derives the two 64-bit seeds used to generate the k bit
+// indices for a key, via a single SHA-256 digest. Taking hash160 by value
+// and hashing hash160[:] keeps this allocation-free: the slice header
+// refers to the parameter's own stack/register storage.
+func hashPair(hash160 [20]byte) (h1, h2 uint64) {
+	sum := sha256simd.Sum256(hash160[:])
+	for i := 0; i < 8; i++ {
+		h1 = h1<<8 | uint64(sum[i])
+	}
+	for i := 8; i < 16; i++ {
+		h2 = h2<<8 | uint64(sum[i])
+	}
+	return h1, h2
+}
+
+// add sets the k bits corresponding to hash160.
+func (f *bloomFilter) add(hash160 [20]byte) {
+	h1, h2 := hashPair(hash160)
+	for i := uint(0); i < f.k; i++ {
+		bit := (h1 + uint64(i)*h2) % f.m
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// mightContain reports whether hash160 may be in the set.
+func (f *bloomFilter) mightContain(hash160 [20]byte) bool {
+	h1, h2 := hashPair(hash160)
+	for i := uint(0); i < f.k; i++ {
+		bit := (h1 + uint64(i)*h2) % f.m
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
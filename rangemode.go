@@ -0,0 +1,222 @@
+package main
+
+/*
+Puzzle Range Search Mode (-mode range)
+
+worker()/workerHD() each attempt an independent, unrepeatable key (random or
+mnemonic-derived). For the well-known Bitcoin "puzzle transaction" outputs,
+the private key is instead known to lie in a specific, narrow 256-bit
+subrange (e.g. bits 66/67/68 of the keyspace), so random search wastes
+almost all of its effort outside that range. -mode range instead assigns
+each worker a disjoint, contiguous slice of [-range-start, -range-end) and
+scans it sequentially, key by key, guaranteeing full coverage with no
+repeats and no misses.
+
+Progress is checkpointed to -checkpoint periodically and on a clean exit, so
+a multi-day scan can be killed and resumed without rescanning already-tried
+keys, and the same range split can be sharded across multiple machines by
+running disjoint -range-start/-range-end pairs on each.
+*/
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcutil"
+)
+
+// KeyRange is one worker's disjoint, contiguous slice of the keyspace to
+// scan sequentially: every key in [Start, End) in order, no repeats.
+type KeyRange struct {
+	ID    string
+	Start *big.Int
+	End   *big.Int // exclusive
+}
+
+// parseKeyRanges splits [start, end) into numWorkers contiguous,
+// non-overlapping KeyRanges of roughly equal size (the last range absorbs
+// any remainder), identified "range-0".."range-(numWorkers-1)".
+func parseKeyRanges(start, end *big.Int, numWorkers int) ([]*KeyRange, error) {
+	if numWorkers < 1 {
+		return nil, fmt.Errorf("numWorkers must be at least 1")
+	}
+	if start.Cmp(end) >= 0 {
+		return nil, fmt.Errorf("-range-start must be less than -range-end")
+	}
+
+	span := new(big.Int).Sub(end, start)
+	step := new(big.Int).Div(span, big.NewInt(int64(numWorkers)))
+	if step.Sign() == 0 {
+		step = big.NewInt(1)
+	}
+
+	ranges := make([]*KeyRange, numWorkers)
+	cursor := new(big.Int).Set(start)
+	for i := 0; i < numWorkers; i++ {
+		rangeStart := new(big.Int).Set(cursor)
+		rangeEnd := new(big.Int).Add(cursor, step)
+		if i == numWorkers-1 || rangeEnd.Cmp(end) > 0 {
+			rangeEnd = new(big.Int).Set(end)
+		}
+		ranges[i] = &KeyRange{ID: fmt.Sprintf("range-%d", i), Start: rangeStart, End: rangeEnd}
+		cursor = rangeEnd
+	}
+	return ranges, nil
+}
+
+// loadRangeCheckpoint reads a previously-saved {range_id: next_key_hex}
+// checkpoint file. A missing file is not an error: it just means every
+// range starts fresh from its own beginning.
+func loadRangeCheckpoint(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var progress map[string]string
+	if err := json.Unmarshal(data, &progress); err != nil {
+		return nil, fmt.Errorf("parsing checkpoint %q: %w", path, err)
+	}
+	return progress, nil
+}
+
+// saveRangeCheckpoint atomically writes progress (range_id -> next_key_hex)
+// to path, via a temp file + rename so a crash mid-write can't corrupt the
+// checkpoint a resume would otherwise trust.
+func saveRangeCheckpoint(path string, progress map[string]string) error {
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// checkpointRanges periodically (and once more right before returning, so
+// the final position is never lost) collects every range's current
+// progress from cursors and persists it to path. Runs until done is closed;
+// callers should wait for it to return before exiting so the last
+// checkpoint is guaranteed to be on disk.
+func checkpointRanges(path string, cursors map[string]*atomic.Value, interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	save := func() {
+		progress := make(map[string]string, len(cursors))
+		for id, cursor := range cursors {
+			if v := cursor.Load(); v != nil {
+				progress[id] = v.(string)
+			}
+		}
+		if err := saveRangeCheckpoint(path, progress); err != nil {
+			log.Printf("checkpoint: failed to save %s: %s", path, err)
+		}
+	}
+
+	for {
+		select {
+		case <-done:
+			save()
+			return
+		case <-ticker.C:
+			save()
+		}
+	}
+}
+
+/*
+rangeWorker is the puzzle-range counterpart of worker(): instead of an
+independent key per attempt, it walks kr.Start..kr.End sequentially,
+publishing its current position to cursor every updateInterval keys so
+checkpointRanges can persist it.
+*/
+func rangeWorker(ctx context.Context, id int, wg *sync.WaitGroup, btcAddresses AddressSet, networks []ChainParams, formatsPresent map[string]*AddressFormatsPresent, kr *KeyRange, cursor *atomic.Value, matchChan chan<- MatchResult, counter *uint64) {
+	defer wg.Done()
+
+	localCounter := uint64(0)
+	const updateInterval = 10000
+
+	scalar := make([]byte, 32)
+	cur := new(big.Int).Set(kr.Start)
+	for cur.Cmp(kr.End) < 0 && ctx.Err() == nil {
+		cur.FillBytes(scalar)
+		privateKey, pubKey := btcec.PrivKeyFromBytes(scalar)
+		hash160 := btcutil.Hash160(pubKey.SerializeCompressed())
+
+		localCounter++
+		if localCounter%updateInterval == 0 {
+			atomic.AddUint64(counter, updateInterval)
+			cursor.Store(cur.Text(16))
+			localCounter = 0
+		}
+
+		var candidates []struct {
+			address string
+			format  string
+		}
+		for _, network := range networks {
+			addrs, err := generateChainAddresses(network, pubKey, hash160, formatsFor(formatsPresent, network.Name))
+			if err != nil {
+				log.Printf("Worker %d: Failed to derive %s addresses: %s", id, network.Name, err)
+				continue
+			}
+			if addrs.CashAddr != "" {
+				candidates = append(candidates, struct {
+					address string
+					format  string
+				}{addrs.CashAddr, network.Name + ":CashAddr"})
+				continue
+			}
+			if addrs.Legacy != "" {
+				candidates = append(candidates, struct {
+					address string
+					format  string
+				}{addrs.Legacy, network.Name + ":P2PKH"})
+			}
+			if addrs.P2SHSegWit != "" {
+				candidates = append(candidates, struct {
+					address string
+					format  string
+				}{addrs.P2SHSegWit, network.Name + ":P2SH-P2WPKH"})
+			}
+			if addrs.Bech32 != "" {
+				candidates = append(candidates, struct {
+					address string
+					format  string
+				}{addrs.Bech32, network.Name + ":P2WPKH"})
+			}
+			if addrs.Taproot != "" {
+				candidates = append(candidates, struct {
+					address string
+					format  string
+				}{addrs.Taproot, network.Name + ":P2TR"})
+			}
+		}
+		for _, candidate := range candidates {
+			if btcAddresses.Contains(candidate.address) {
+				fmt.Printf("\n*** MATCH FOUND! ***\nAddress: %s (%s)\nKey range: %s\n\n", candidate.address, candidate.format, kr.ID)
+				matchChan <- MatchResult{privateKey: privateKey, address: candidate.address, format: fmt.Sprintf("%s [%s]", candidate.format, kr.ID)}
+			}
+		}
+
+		cur.Add(cur, big.NewInt(1))
+	}
+
+	// Record the final position: kr.End if the range was fully exhausted (so
+	// a resume doesn't rescan it), or wherever cur got to if ctx was
+	// cancelled first (so a resume picks up exactly where this left off).
+	cursor.Store(cur.Text(16))
+}
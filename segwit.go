@@ -0,0 +1,151 @@
+package main
+
+/*
+SegWit and Taproot Address Generation
+
+This file extends the legacy P2PKH generator with the three other address
+families that a real Bitcoin private key can produce:
+
+  - P2SH-P2WPKH ("3..."): a P2SH wrapper around a P2WPKH witness program,
+    still Base58Check encoded so it is compatible with wallets/miners that
+    predate native SegWit.
+  - P2WPKH ("bc1q..."): native SegWit, witness version 0, Bech32 encoded
+    (BIP-173).
+  - P2TR ("bc1p..."): Taproot, witness version 1, Bech32m encoded (BIP-350),
+    using the BIP-341 key-path-only output key Q = P + int(H_TapTweak(P_x))*G.
+
+The Bech32/Bech32m encoding and the Taproot tweak themselves live in the
+addr subpackage, which bench also imports so its benchmarks exercise the
+exact same code path rather than a duplicate.
+*/
+
+import (
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/base58"
+	sha256simd "github.com/minio/sha256-simd"
+
+	"github.com/Asylian21/btc-brute-force/addr"
+)
+
+// generateP2WPKH encodes a 20-byte Hash160 as a native SegWit (bc1q...)
+// address.
+func generateP2WPKH(hash160 []byte) (string, error) {
+	return addr.EncodeP2WPKH(hash160), nil
+}
+
+// generateP2SHWPKH wraps a P2WPKH witness program in a P2SH output so
+// pre-SegWit wallets and pools can still pay it, producing a legacy-looking
+// "3..." address. The redeem script is OP_0 <20-byte-hash160>.
+func generateP2SHWPKH(hash160 []byte) (string, error) {
+	redeemScript := make([]byte, 0, 22)
+	redeemScript = append(redeemScript, 0x00, 0x14)
+	redeemScript = append(redeemScript, hash160...)
+
+	scriptHash := btcutil.Hash160(redeemScript)
+
+	buf := make([]byte, 0, 25)
+	buf = append(buf, 0x05) // mainnet P2SH version byte
+	buf = append(buf, scriptHash...)
+
+	h1 := sha256simd.Sum256(buf)
+	h2 := sha256simd.Sum256(h1[:])
+	buf = append(buf, h2[:4]...)
+
+	return base58.Encode(buf), nil
+}
+
+// generateP2TR derives the BIP-341 Taproot output key from a compressed
+// public key and Bech32m-encodes it as a "bc1p..." address.
+func generateP2TR(pub *btcec.PublicKey) (string, error) {
+	xonly, err := addr.TaprootOutputKey(pub)
+	if err != nil {
+		return "", err
+	}
+	return addr.EncodeP2TR(xonly), nil
+}
+
+// ============================================================================
+// ALL-FORMATS GENERATION
+// ============================================================================
+
+// GeneratedAddresses holds every address format derivable from a single
+// secp256k1 key pair, so a worker can check all of them against the target
+// set in one pass without repeating the (expensive) key generation step.
+type GeneratedAddresses struct {
+	Legacy     string // P2PKH,      "1..."
+	P2SHSegWit string // P2SH-P2WPKH, "3..."
+	Bech32     string // P2WPKH,      "bc1q..."
+	Taproot    string // P2TR,        "bc1p..."
+}
+
+// generateAllAddresses generates one private key and derives the full family
+// of mainnet address formats it can produce: legacy P2PKH, P2SH-wrapped
+// SegWit, native Bech32 SegWit, and Bech32m Taproot.
+func generateAllAddresses() (*btcec.PrivateKey, *GeneratedAddresses, error) {
+	privateKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	pubKey := privateKey.PubKey()
+	pubKeyBytes := pubKey.SerializeCompressed()
+	hash160 := btcutil.Hash160(pubKeyBytes)
+
+	legacy, err := generateLegacyAddress(hash160)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p2shSegwit, err := generateP2SHWPKH(hash160)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bech32Addr, err := generateP2WPKH(hash160)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	taproot, err := generateP2TR(pubKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return privateKey, &GeneratedAddresses{
+		Legacy:     legacy,
+		P2SHSegWit: p2shSegwit,
+		Bech32:     bech32Addr,
+		Taproot:    taproot,
+	}, nil
+}
+
+// generateKeyAndHash160 generates one private key and returns its public key
+// and Hash160, the shared inputs every chain's address formats are derived
+// from. Factored out of generateAllAddresses so the multi-chain worker can
+// generate a key once and check it against several ChainParams.
+func generateKeyAndHash160() (*btcec.PrivateKey, *btcec.PublicKey, []byte, error) {
+	privateKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	pubKey := privateKey.PubKey()
+	hash160 := btcutil.Hash160(pubKey.SerializeCompressed())
+	return privateKey, pubKey, hash160, nil
+}
+
+// generateLegacyAddress Base58Check-encodes a Hash160 as a mainnet P2PKH
+// address. Factored out of generateKeyAndAddress so generateAllAddresses can
+// reuse it without generating a second key.
+func generateLegacyAddress(hash160 []byte) (string, error) {
+	buf := bufferPool.Get().([]byte)[:0]
+	defer bufferPool.Put(buf)
+
+	buf = append(buf, 0x00)
+	buf = append(buf, hash160...)
+
+	h1 := sha256simd.Sum256(buf)
+	h2 := sha256simd.Sum256(h1[:])
+	buf = append(buf, h2[:4]...)
+
+	return base58.Encode(buf), nil
+}
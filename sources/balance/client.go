@@ -0,0 +1,219 @@
+// Package balance implements a batching client for blockchain.info-style
+// "balance?active=addr1|addr2|..." APIs: it collects candidate addresses
+// pushed onto a channel, groups them into batches on a size/time debounce,
+// and reports back any address the API says has ever held a balance or
+// seen a transaction. This lets a search mode flag addresses that are
+// funded (or have history) without needing them in a local target set at
+// all - useful since the overwhelming majority of real Bitcoin activity
+// isn't in any wordlist.
+package balance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Defaults used when the corresponding Checker field is left at its zero
+// value.
+const (
+	DefaultEndpoint   = "https://blockchain.info/balance?active=%s"
+	DefaultBatchSize  = 75
+	DefaultDebounce   = 2 * time.Second
+	DefaultMaxRetries = 3
+)
+
+// Candidate is one generated address offered up for a live balance check.
+// PrivateKeyHex is carried through opaquely (this package has no dependency
+// on btcec) so a Match can be turned back into a usable key by the caller.
+type Candidate struct {
+	Address       string
+	PrivateKeyHex string
+	Format        string
+}
+
+// Match is a Candidate the API reported as funded or previously used.
+type Match struct {
+	Candidate
+	FinalBalance int64
+	NTx          int
+}
+
+// balanceEntry is one address's entry in a blockchain.info-style
+// "balance?active=..." response: {"addr": {"final_balance": 0, "n_tx": 0, ...}}.
+type balanceEntry struct {
+	FinalBalance int64 `json:"final_balance"`
+	NTx          int   `json:"n_tx"`
+}
+
+// Checker batches Candidates pushed by one or more producers and queries
+// Endpoint for each batch, forwarding any funded-or-used address to the
+// onMatch callback passed to Run. The zero value is ready to use; every
+// field below falls back to its Default* constant when left unset.
+type Checker struct {
+	// Endpoint is a URL template with a single %s for the pipe-joined
+	// address list, e.g. "https://blockchain.info/balance?active=%s".
+	Endpoint string
+
+	// BatchSize is how many addresses accumulate before a batch is sent
+	// early, without waiting for Debounce to elapse.
+	BatchSize int
+
+	// Debounce is the longest a partial batch waits for more candidates
+	// before being sent anyway.
+	Debounce time.Duration
+
+	// MaxRetries is how many times a failing (non-200, or transport error)
+	// request is retried, with exponential backoff, before the batch is
+	// dropped and logged.
+	MaxRetries int
+
+	// HTTPClient lets tests substitute a fake transport; defaults to
+	// http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Run collects candidates off the channel, querying Endpoint for each
+// completed batch, until candidates is closed or ctx is cancelled. Any
+// batch still pending at that point is flushed (best-effort, respecting
+// ctx) before Run returns.
+func (c *Checker) Run(ctx context.Context, candidates <-chan Candidate, onMatch func(Match)) {
+	batchSize := c.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	debounce := c.Debounce
+	if debounce <= 0 {
+		debounce = DefaultDebounce
+	}
+
+	timer := time.NewTimer(debounce)
+	defer timer.Stop()
+
+	batch := make([]Candidate, 0, batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		c.query(ctx, batch, onMatch)
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			flush()
+			return
+		case cand, ok := <-candidates:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, cand)
+			if len(batch) >= batchSize {
+				flush()
+				if !timer.Stop() {
+					<-timer.C
+				}
+				timer.Reset(debounce)
+			}
+		case <-timer.C:
+			flush()
+			timer.Reset(debounce)
+		}
+	}
+}
+
+// query issues (and retries on failure) the balance lookup for one batch,
+// forwarding every funded-or-used address in the response to onMatch.
+func (c *Checker) query(ctx context.Context, batch []Candidate, onMatch func(Match)) {
+	addrs := make([]string, len(batch))
+	for i, cand := range batch {
+		addrs[i] = cand.Address
+	}
+
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	url := fmt.Sprintf(endpoint, strings.Join(addrs, "|"))
+
+	body, err := c.fetchWithRetry(ctx, url)
+	if err != nil {
+		log.Printf("balance: giving up on batch of %d addresses: %s", len(batch), err)
+		return
+	}
+
+	var results map[string]balanceEntry
+	if err := json.Unmarshal(body, &results); err != nil {
+		log.Printf("balance: malformed response for batch of %d addresses: %s", len(batch), err)
+		return
+	}
+
+	for _, cand := range batch {
+		entry, ok := results[cand.Address]
+		if !ok || (entry.FinalBalance == 0 && entry.NTx == 0) {
+			continue
+		}
+		onMatch(Match{Candidate: cand, FinalBalance: entry.FinalBalance, NTx: entry.NTx})
+	}
+}
+
+// fetchWithRetry issues the GET request, retrying on a non-200 response or
+// transport error with exponential backoff (1s, 2s, 4s, ...) up to
+// MaxRetries times.
+func (c *Checker) fetchWithRetry(ctx context.Context, url string) ([]byte, error) {
+	maxRetries := c.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = DefaultMaxRetries
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			backoff := time.Duration(1<<uint(attempt-1)) * time.Second
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+			}
+		}
+
+		body, err := c.fetchOnce(ctx, client, url)
+		if err == nil {
+			return body, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+func (c *Checker) fetchOnce(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("building request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+	return body, nil
+}
@@ -0,0 +1,129 @@
+package balance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newFakeServer(t *testing.T, funded map[string]balanceEntry) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		active := strings.Split(r.URL.Query().Get("active"), "|")
+		results := make(map[string]balanceEntry, len(active))
+		for _, addr := range active {
+			results[addr] = funded[addr] // zero value for unfunded addresses
+		}
+		if err := json.NewEncoder(w).Encode(results); err != nil {
+			t.Errorf("encode response: %s", err)
+		}
+	}))
+}
+
+func TestCheckerRunReportsFundedAddresses(t *testing.T) {
+	funded := map[string]balanceEntry{
+		"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa": {FinalBalance: 6800000000, NTx: 2},
+	}
+	server := newFakeServer(t, funded)
+	defer server.Close()
+
+	checker := &Checker{
+		Endpoint:  server.URL + "?active=%s",
+		BatchSize: 2,
+		Debounce:  50 * time.Millisecond,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	candidates := make(chan Candidate, 4)
+	candidates <- Candidate{Address: "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", PrivateKeyHex: "aa"}
+	candidates <- Candidate{Address: "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", PrivateKeyHex: "bb"}
+	close(candidates)
+
+	var mu sync.Mutex
+	var got []Match
+	done := make(chan struct{})
+	go func() {
+		checker.Run(ctx, candidates, func(m Match) {
+			mu.Lock()
+			got = append(got, m)
+			mu.Unlock()
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return after candidates closed")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 1 {
+		t.Fatalf("got %d matches, want 1: %+v", len(got), got)
+	}
+	if got[0].Address != "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa" || got[0].FinalBalance != 6800000000 || got[0].NTx != 2 {
+		t.Errorf("unexpected match: %+v", got[0])
+	}
+}
+
+func TestCheckerRunRetriesOnFailure(t *testing.T) {
+	var attempts int
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		n := attempts
+		mu.Unlock()
+		if n < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa":{"final_balance":1,"n_tx":1}}`)
+	}))
+	defer server.Close()
+
+	checker := &Checker{
+		Endpoint:   server.URL + "?active=%s",
+		BatchSize:  1,
+		Debounce:   50 * time.Millisecond,
+		MaxRetries: 3,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	candidates := make(chan Candidate, 1)
+	candidates <- Candidate{Address: "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", PrivateKeyHex: "aa"}
+	close(candidates)
+
+	var got []Match
+	done := make(chan struct{})
+	go func() {
+		checker.Run(ctx, candidates, func(m Match) {
+			mu.Lock()
+			got = append(got, m)
+			mu.Unlock()
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after candidates closed")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 1 {
+		t.Fatalf("got %d matches, want 1", len(got))
+	}
+}
@@ -0,0 +1,128 @@
+// Package blockbook implements a minimal client for a Blockbook
+// (https://github.com/trezor/blockbook) style websocket API: it subscribes
+// to newly-seen transactions and reports every output address it observes,
+// so a caller can track the actively-used address universe at runtime
+// instead of relying on a static file dump. The connection auto-reconnects
+// with a fixed delay so the feed survives the server restarting.
+package blockbook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// DefaultReconnectDelay is how long Client waits between reconnect attempts
+// when ReconnectDelay is left at zero.
+const DefaultReconnectDelay = 5 * time.Second
+
+// subscribeRequest is sent once per connection to start the feed.
+type subscribeRequest struct {
+	ID     string      `json:"id"`
+	Method string      `json:"method"`
+	Params interface{} `json:"params"`
+}
+
+// notification is a single pushed "new transaction" message. Only the
+// fields needed to recover output addresses are modeled.
+type notification struct {
+	Data struct {
+		Vout []struct {
+			Addresses []string `json:"addresses"`
+		} `json:"vout"`
+	} `json:"data"`
+}
+
+// Client is an auto-reconnecting Blockbook websocket client that funnels
+// every address it sees in a new transaction's outputs to OnAddress.
+type Client struct {
+	URL       string
+	OnAddress func(address string)
+
+	// ReconnectDelay overrides DefaultReconnectDelay when non-zero.
+	ReconnectDelay time.Duration
+
+	// Dial lets tests substitute a fake dialer; defaults to
+	// websocket.DefaultDialer.DialContext.
+	Dial func(ctx context.Context, url string) (*websocket.Conn, error)
+}
+
+// Run connects and processes the feed until ctx is cancelled, reconnecting
+// after ReconnectDelay on any dial or read error. It only returns once ctx
+// is done.
+func (c *Client) Run(ctx context.Context) {
+	delay := c.ReconnectDelay
+	if delay == 0 {
+		delay = DefaultReconnectDelay
+	}
+
+	for ctx.Err() == nil {
+		if err := c.runOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("blockbook: %s, reconnecting in %s", err, delay)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (c *Client) dial(ctx context.Context) (*websocket.Conn, error) {
+	if c.Dial != nil {
+		return c.Dial(ctx, c.URL)
+	}
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, c.URL, nil)
+	return conn, err
+}
+
+// runOnce holds a single connection open until it errors out, dispatching
+// every new-transaction notification's addresses to OnAddress as it arrives.
+func (c *Client) runOnce(ctx context.Context) error {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("dial: %w", err)
+	}
+	defer conn.Close()
+
+	// ReadMessage below has no ctx awareness of its own, so close the
+	// connection out from under it when ctx is cancelled to unblock the
+	// read loop instead of leaving Run stuck until the next server-side
+	// disconnect.
+	closed := make(chan struct{})
+	defer close(closed)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-closed:
+		}
+	}()
+
+	req := subscribeRequest{ID: "subscribeNewTransaction", Method: "subscribeNewTransaction", Params: struct{}{}}
+	if err := conn.WriteJSON(req); err != nil {
+		return fmt.Errorf("subscribe: %w", err)
+	}
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return fmt.Errorf("read: %w", err)
+		}
+
+		var note notification
+		if err := json.Unmarshal(message, &note); err != nil {
+			log.Printf("blockbook: skipping unparseable frame: %s", err)
+			continue
+		}
+		for _, out := range note.Data.Vout {
+			for _, addr := range out.Addresses {
+				c.OnAddress(addr)
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package blockbook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// canned frames a fake server pipes to the client, one new-transaction
+// notification per frame.
+var cannedFrames = []string{
+	`{"id":"subscribeNewTransaction","data":{"vout":[{"n":0,"addresses":["1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"]}]}}`,
+	`{"id":"subscribeNewTransaction","data":{"vout":[{"n":0,"addresses":["bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"]},{"n":1,"addresses":["3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy"]}]}}`,
+}
+
+func newFakeServer(t *testing.T, frames []string) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %s", err)
+			return
+		}
+		defer conn.Close()
+
+		// Drain the subscribe request before pushing canned frames.
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+		for _, frame := range frames {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(frame)); err != nil {
+				return
+			}
+		}
+		// Keep the connection open until the client goes away so Run
+		// doesn't spin through a reconnect loop mid-test.
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+}
+
+func TestClientRunParsesCannedFrames(t *testing.T) {
+	server := newFakeServer(t, cannedFrames)
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	var mu sync.Mutex
+	var got []string
+	client := &Client{
+		URL: wsURL,
+		OnAddress: func(address string) {
+			mu.Lock()
+			defer mu.Unlock()
+			got = append(got, address)
+		},
+		ReconnectDelay: time.Hour, // don't reconnect mid-assertion
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		client.Run(ctx)
+		close(done)
+	}()
+
+	want := []string{
+		"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa",
+		"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+		"3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy",
+	}
+	deadline := time.After(time.Second)
+	for {
+		mu.Lock()
+		n := len(got)
+		mu.Unlock()
+		if n >= len(want) {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for addresses, got %v so far", got)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i, addr := range want {
+		if got[i] != addr {
+			t.Errorf("got[%d] = %q, want %q", i, got[i], addr)
+		}
+	}
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,147 @@
+package main
+
+/*
+Vanity Prefix Search Mode (-mode vanity)
+
+worker() checks every generated candidate address for set-membership
+against a loaded target list. vanityWorker instead checks it against a
+small list of desired prefixes (-vanity-prefixes) - the classic "find me a
+1Love... address" vanity-generation use case, and structurally identical to
+a proof-of-work "N leading characters" search. Since address encoding
+(Base58Check/Bech32) is already the expensive part of generating a
+candidate, the only thing vanityWorker saves over a target-set search is
+the lookup itself: a prefix compare is cheaper than even a Bloom filter
+probe, and needs no target file at all.
+*/
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// VanityTarget is the parsed -vanity-prefixes/-vanity-case-insensitive
+// configuration for -mode vanity.
+type VanityTarget struct {
+	Prefixes        []string
+	CaseInsensitive bool
+}
+
+// parseVanityPrefixes splits the comma-separated -vanity-prefixes flag
+// value into a trimmed, non-empty prefix list, lower-casing each entry up
+// front when caseInsensitive so matchVanityPrefix never has to repeat that
+// work per candidate.
+func parseVanityPrefixes(flagValue string, caseInsensitive bool) ([]string, error) {
+	var prefixes []string
+	for _, raw := range strings.Split(flagValue, ",") {
+		prefix := strings.TrimSpace(raw)
+		if prefix == "" {
+			continue
+		}
+		if caseInsensitive {
+			prefix = strings.ToLower(prefix)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	if len(prefixes) == 0 {
+		return nil, fmt.Errorf("no prefixes given")
+	}
+	return prefixes, nil
+}
+
+// matchVanityPrefix does a cheap string-prefix compare of address against
+// every entry in target.Prefixes, returning the first one that matches.
+func matchVanityPrefix(address string, target *VanityTarget) (string, bool) {
+	candidate := address
+	if target.CaseInsensitive {
+		candidate = strings.ToLower(candidate)
+	}
+	for _, prefix := range target.Prefixes {
+		if strings.HasPrefix(candidate, prefix) {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+/*
+vanityWorker is the -mode vanity counterpart of worker(): instead of an
+AddressSet lookup, every derived candidate gets a prefix compare against
+target, and a hit is forwarded to matchChan with the matched prefix
+annotated on MatchResult.vanityPrefix.
+*/
+func vanityWorker(ctx context.Context, id int, wg *sync.WaitGroup, target *VanityTarget, networks []ChainParams, formatsPresent map[string]*AddressFormatsPresent, matchChan chan<- MatchResult, counter *uint64) {
+	defer wg.Done()
+
+	localCounter := uint64(0)
+	const updateInterval = 10000
+
+	for ctx.Err() == nil {
+		privateKey, pubKey, hash160, err := generateKeyAndHash160()
+		if err != nil {
+			log.Printf("Worker %d: Failed to generate key: %s", id, err)
+			continue
+		}
+
+		localCounter++
+		if localCounter%updateInterval == 0 {
+			atomic.AddUint64(counter, updateInterval)
+			localCounter = 0
+		}
+
+		var candidates []struct {
+			address string
+			format  string
+		}
+		for _, network := range networks {
+			addrs, err := generateChainAddresses(network, pubKey, hash160, formatsFor(formatsPresent, network.Name))
+			if err != nil {
+				log.Printf("Worker %d: Failed to derive %s addresses: %s", id, network.Name, err)
+				continue
+			}
+			if addrs.CashAddr != "" {
+				candidates = append(candidates, struct {
+					address string
+					format  string
+				}{addrs.CashAddr, network.Name + ":CashAddr"})
+				continue
+			}
+			if addrs.Legacy != "" {
+				candidates = append(candidates, struct {
+					address string
+					format  string
+				}{addrs.Legacy, network.Name + ":P2PKH"})
+			}
+			if addrs.P2SHSegWit != "" {
+				candidates = append(candidates, struct {
+					address string
+					format  string
+				}{addrs.P2SHSegWit, network.Name + ":P2SH-P2WPKH"})
+			}
+			if addrs.Bech32 != "" {
+				candidates = append(candidates, struct {
+					address string
+					format  string
+				}{addrs.Bech32, network.Name + ":P2WPKH"})
+			}
+			if addrs.Taproot != "" {
+				candidates = append(candidates, struct {
+					address string
+					format  string
+				}{addrs.Taproot, network.Name + ":P2TR"})
+			}
+		}
+
+		for _, candidate := range candidates {
+			prefix, ok := matchVanityPrefix(candidate.address, target)
+			if !ok {
+				continue
+			}
+			fmt.Printf("\n*** VANITY MATCH! ***\nAddress: %s (%s)\nPrefix: %s\n\n", candidate.address, candidate.format, prefix)
+			matchChan <- MatchResult{privateKey: privateKey, address: candidate.address, format: candidate.format, vanityPrefix: prefix}
+		}
+	}
+}